@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Actor is anything in the sea besides the player and the cannonballs.
+// Game keeps all of them in one list in the order they spawned.
+type Actor interface {
+	// Body is the entity the actor moves, draws and collides with.
+	Body() *Entity
+	Layer() Layer
+
+	// Update moves the actor one frame, including any shooting it does
+	// and the particles it gives off while dying.
+	Update(g *Game)
+	Draw()
+	Free()
+
+	// Gone reports whether the actor can be removed.
+	Gone() bool
+
+	// Touch is called when the player runs into the actor.
+	Touch(g *Game)
+
+	// Hit is called when a player cannonball runs into the actor and
+	// reports whether it took the hit. Ordinary cannonballs are used up
+	// by a hit, special ones go on unless Hit marks them dead.
+	Hit(g *Game, c *Cannon) bool
+
+	// Value is the score for a hit by the cannonball.
+	Value(c *Cannon) int
+}
+
+// ActorKind is a kind of actor maps can spawn by name.
+type ActorKind struct {
+	New      func(g *Game, ev SpawnEvent) Actor
+	Variants []string
+	Unique   bool // only one can be afloat at a time
+}
+
+var actorKinds = make(map[string]*ActorKind)
+
+// RegisterActor makes a kind of actor available to maps under the name.
+func RegisterActor(name string, kind ActorKind) {
+	if _, found := actorKinds[name]; found {
+		panic(fmt.Sprintf("actor %q registered twice", name))
+	}
+	actorKinds[name] = &kind
+}
+
+// ActorNames lists the registered kinds in alphabetical order.
+func ActorNames() []string {
+	var names []string
+	for name := range actorKinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
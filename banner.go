@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"text/template"
+
+	"github.com/qeedquan/go-media/sdl/sdlimage/sdlcolor"
+)
+
+// Banner is a message shown over the sea during a phase. It is queued At
+// frames into the phase, fades in, holds and fades out over the given
+// number of frames.
+type Banner struct {
+	Text    string
+	At      int
+	FadeIn  int
+	Hold    int
+	FadeOut int
+
+	tmpl *template.Template
+}
+
+// BannerContext is what banner templates can refer to.
+type BannerContext struct {
+	Name  string
+	Score int
+	Phase int
+	Color string
+}
+
+type bannerEntry struct {
+	Text    string `json:"text"`
+	At      int    `json:"at"`
+	FadeIn  *int   `json:"in"`
+	Hold    *int   `json:"hold"`
+	FadeOut *int   `json:"out"`
+}
+
+// defaultBanner is the timing of a banner given as a plain string.
+var defaultBanner = Banner{FadeIn: 10, Hold: 50, FadeOut: 60}
+
+// parseBanners reads the banners of a phase, written either as a single
+// string or as a list of strings and timed entries.
+func parseBanners(buf json.RawMessage) ([]*Banner, error) {
+	var list []json.RawMessage
+	if len(buf) > 0 && buf[0] == '[' {
+		err := json.Unmarshal(buf, &list)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		list = []json.RawMessage{buf}
+	}
+
+	var banners []*Banner
+	for i, item := range list {
+		b, err := parseBanner(item)
+		if err != nil {
+			return nil, fmt.Errorf("banner %v: %v", i, err)
+		}
+		if b != nil {
+			banners = append(banners, b)
+		}
+	}
+	return banners, nil
+}
+
+// parseBanner returns nil for empty text, which keeps a phase quiet.
+func parseBanner(buf json.RawMessage) (*Banner, error) {
+	b := defaultBanner
+	if len(buf) > 0 && buf[0] == '"' {
+		err := json.Unmarshal(buf, &b.Text)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var e bannerEntry
+		dec := json.NewDecoder(bytes.NewReader(buf))
+		dec.DisallowUnknownFields()
+		err := dec.Decode(&e)
+		if err != nil {
+			return nil, err
+		}
+		b.Text, b.At = e.Text, e.At
+		if e.FadeIn != nil {
+			b.FadeIn = *e.FadeIn
+		}
+		if e.Hold != nil {
+			b.Hold = *e.Hold
+		}
+		if e.FadeOut != nil {
+			b.FadeOut = *e.FadeOut
+		}
+	}
+
+	switch {
+	case b.Text == "":
+		return nil, nil
+	case b.At < 0:
+		return nil, fmt.Errorf("at %v is negative", b.At)
+	case b.FadeIn < 0 || b.Hold < 0 || b.FadeOut < 0:
+		return nil, fmt.Errorf("timings %v, %v, %v must not be negative", b.FadeIn, b.Hold, b.FadeOut)
+	case b.FadeIn+b.Hold+b.FadeOut == 0:
+		return nil, fmt.Errorf("banner is never shown")
+	}
+
+	var err error
+	b.tmpl, err = template.New("").Parse(b.Text)
+	if err != nil {
+		return nil, err
+	}
+	err = b.tmpl.Execute(io.Discard, BannerContext{})
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (b *Banner) Length() int {
+	return b.FadeIn + b.Hold + b.FadeOut
+}
+
+// Alpha returns the opacity of the banner t frames after it appeared.
+func (b *Banner) Alpha(t int) uint8 {
+	switch {
+	case t < b.FadeIn:
+		return uint8(t * 255 / b.FadeIn)
+	case t < b.FadeIn+b.Hold:
+		return 255
+	case t < b.Length():
+		return uint8((b.Length() - t) * 255 / b.FadeOut)
+	}
+	return 0
+}
+
+type shownBanner struct {
+	*Banner
+	text string
+	t    int
+}
+
+// Banners shows queued banners one after another.
+type Banners struct {
+	queue []shownBanner
+}
+
+func (q *Banners) Reset() {
+	q.queue = q.queue[:0]
+}
+
+// Push fills in the banner's template now, so the text shows the score
+// and phase of the moment it was queued. A template that fails shows its
+// text as written.
+func (q *Banners) Push(b *Banner, ctx BannerContext) {
+	buf := new(bytes.Buffer)
+	text := b.Text
+	err := b.tmpl.Execute(buf, ctx)
+	if err != nil {
+		// templates are checked against an empty context when the map
+		// loads, other values can still fail
+		log.SetPrefix("banner: ")
+		log.Print(err)
+	} else {
+		text = buf.String()
+	}
+	q.queue = append(q.queue, shownBanner{b, text, 0})
+}
+
+func (q *Banners) Update() {
+	if len(q.queue) == 0 {
+		return
+	}
+	b := &q.queue[0]
+	if b.t++; b.t >= b.Length() {
+		q.queue = append(q.queue[:0], q.queue[1:]...)
+	}
+}
+
+func (q *Banners) Draw() {
+	if len(q.queue) == 0 {
+		return
+	}
+	b := &q.queue[0]
+	_, th := MeasureText(smallFont, b.text)
+	style := Small().Aligned(AlignCenter).Shadow(sdlcolor.White).Faded(b.Alpha(b.t))
+	DrawText(style, W/2, (H-th)/2, b.text)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBannersPush(t *testing.T) {
+	tests := []struct {
+		text string
+		ctx  BannerContext
+		want string
+	}{
+		{`"Phase {{.Phase}}"`, BannerContext{Phase: 3}, "Phase 3"},
+		{`"Well done, {{.Name}}"`, BannerContext{Name: "Pekuja"}, "Well done, Pekuja"},
+		// fine for the empty context the map is checked with, fails here
+		{`"{{if .Score}}{{index .Name 9}}{{end}} ahoy"`, BannerContext{Name: "Hai", Score: 10}, "{{if .Score}}{{index .Name 9}}{{end}} ahoy"},
+	}
+	for _, tt := range tests {
+		b, err := parseBanner(json.RawMessage(tt.text))
+		if err != nil {
+			t.Fatalf("%v: %v", tt.text, err)
+		}
+		var q Banners
+		q.Push(b, tt.ctx)
+		if len(q.queue) != 1 || q.queue[0].text != tt.want {
+			t.Errorf("%v: queued %+v, want %q", tt.text, q.queue, tt.want)
+		}
+	}
+}
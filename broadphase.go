@@ -0,0 +1,139 @@
+package main
+
+import "image"
+
+// Layer says what an entity is for collision purposes. The game only asks
+// for pairs between layers that can interact.
+type Layer int
+
+const (
+	LayerPlayer Layer = iota
+	LayerEnemy
+	LayerPlayerShot
+	LayerEnemyShot
+	LayerPickup
+	NumLayers
+)
+
+// Body is an entity entered into the broadphase. Kind and Index are for
+// the caller to find its way back from a pair to what the entity is.
+type Body struct {
+	Layer  Layer
+	Kind   int
+	Index  int
+	Entity *Entity
+	Rect   image.Rectangle
+	id     int
+}
+
+type Pair struct {
+	A, B *Body
+}
+
+// The grid covers the screen plus a margin for entities coming in from
+// the sides. Anything further out lands in the border cells, which is
+// only slower, not wrong.
+const (
+	CellSize   = 32
+	gridMargin = 2 * CellSize
+	gridCols   = (W + 2*gridMargin + CellSize - 1) / CellSize
+	gridRows   = (H + 2*gridMargin + CellSize - 1) / CellSize
+)
+
+// Broadphase buckets the collision rectangles of a frame into a uniform
+// grid per layer, so finding what may touch an entity only looks at the
+// cells it covers instead of at every other entity.
+type Broadphase struct {
+	bodies []Body
+	cells  [NumLayers][gridRows * gridCols][]int
+	seen   []int
+	query  int
+	pairs  []Pair
+}
+
+func (b *Broadphase) Reset() {
+	for l := range b.cells {
+		for c := range b.cells[l] {
+			b.cells[l][c] = b.cells[l][c][:0]
+		}
+	}
+	b.bodies = b.bodies[:0]
+}
+
+func (b *Broadphase) Add(layer Layer, kind, index int, e *Entity) {
+	r := collisionRect(e)
+	if r.Empty() {
+		return
+	}
+
+	id := len(b.bodies)
+	b.bodies = append(b.bodies, Body{layer, kind, index, e, r, id})
+
+	x0, y0, x1, y1 := gridSpan(r)
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			c := &b.cells[layer][y*gridCols+x]
+			*c = append(*c, id)
+		}
+	}
+}
+
+// Pairs returns the bodies of layer l1 with overlapping rectangles in
+// layer l2, ordered by the order they were added in. The result is reused
+// by the next call.
+func (b *Broadphase) Pairs(l1, l2 Layer) []Pair {
+	b.pairs = b.pairs[:0]
+	for len(b.seen) < len(b.bodies) {
+		b.seen = append(b.seen, 0)
+	}
+
+	for i := range b.bodies {
+		p := &b.bodies[i]
+		if p.Layer != l1 {
+			continue
+		}
+
+		b.query++
+		start := len(b.pairs)
+		x0, y0, x1, y1 := gridSpan(p.Rect)
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				for _, j := range b.cells[l2][y*gridCols+x] {
+					if b.seen[j] == b.query || j == i {
+						continue
+					}
+					b.seen[j] = b.query
+
+					q := &b.bodies[j]
+					if p.Rect.Overlaps(q.Rect) {
+						b.pairs = append(b.pairs, Pair{p, q})
+					}
+				}
+			}
+		}
+
+		// cells are visited in grid order, put the candidates of this
+		// body back in the order they were added
+		c := b.pairs[start:]
+		for k := 1; k < len(c); k++ {
+			for m := k; m > 0 && c[m].B.id < c[m-1].B.id; m-- {
+				c[m], c[m-1] = c[m-1], c[m]
+			}
+		}
+	}
+	return b.pairs
+}
+
+func gridSpan(r image.Rectangle) (x0, y0, x1, y1 int) {
+	cell := func(v, n int) int {
+		v = (v + gridMargin) / CellSize
+		if v < 0 {
+			return 0
+		}
+		if v >= n {
+			return n - 1
+		}
+		return v
+	}
+	return cell(r.Min.X, gridCols), cell(r.Min.Y, gridRows), cell(r.Max.X-1, gridCols), cell(r.Max.Y-1, gridRows)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// Buoyancy is how something floats. Once its bottom is Draft pixels below
+// the water line the waves damp its vertical speed by Drag and push it up,
+// by Lift when it is fully under and by Surface per pixel of depth when it
+// is not. Heavier things are pushed less. Afloat it leans with Tilt times
+// the slope of the waves, rocks by Bob degrees and turns Righting of the
+// way to that angle each frame; sinking it turns Capsize of the way to its
+// target angle instead.
+type Buoyancy struct {
+	Mass     float64 `json:"mass"`
+	Drag     float64 `json:"drag"`
+	Lift     float64 `json:"lift"`
+	Surface  float64 `json:"surface"`
+	Draft    float64 `json:"draft"`
+	Tilt     float64 `json:"tilt"`
+	Bob      float64 `json:"bob"`
+	Righting float64 `json:"righting"`
+	Capsize  float64 `json:"capsize"`
+}
+
+var buoyancies map[string]Buoyancy
+
+// LoadBuoyancy returns the named entry of the buoyancy table in the
+// resource directory.
+func LoadBuoyancy(name string) Buoyancy {
+	log.SetPrefix("buoyancy: ")
+	if buoyancies == nil {
+		filename := filepath.Join(config.Resource, "buoyancy.json")
+		buf, err := os.ReadFile(filename)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		buoyancies, err = parseBuoyancy(buf)
+		if err != nil {
+			log.Fatalf("%v: %v", filename, err)
+		}
+	}
+
+	b, found := buoyancies[name]
+	if !found {
+		log.Fatalf("no entry for %q", name)
+	}
+	return b
+}
+
+func parseBuoyancy(buf []byte) (map[string]Buoyancy, error) {
+	var t map[string]Buoyancy
+
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&t)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, b := range t {
+		switch {
+		case b.Mass <= 0:
+			return nil, fmt.Errorf("%v: mass %v is not positive", name, b.Mass)
+		case b.Drag < 0 || b.Drag > 1:
+			return nil, fmt.Errorf("%v: drag %v is not between 0 and 1", name, b.Drag)
+		case b.Righting < 0 || b.Righting > 1:
+			return nil, fmt.Errorf("%v: righting %v is not between 0 and 1", name, b.Righting)
+		case b.Capsize < 0 || b.Capsize > 1:
+			return nil, fmt.Errorf("%v: capsize %v is not between 0 and 1", name, b.Capsize)
+		}
+	}
+	return t, nil
+}
+
+// Float moves the entity a frame on the waves and reports whether it was
+// in the water.
+func (b *Buoyancy) Float(e *Entity) bool {
+	m := e.Image()
+	l := WaterRegion(e.Pos, m)
+
+	bottom := e.Pos.Bottom(m)
+	wet := bottom > l[1]+b.Draft
+	if wet {
+		e.Vel.Y *= b.Drag
+		if e.Pos.Y > l[1] {
+			e.Vel.Y -= b.Lift / b.Mass
+		} else {
+			e.Vel.Y -= b.Surface * (bottom - l[1]) / b.Mass
+		}
+
+		e.TargetAngle = b.Tilt*math.Atan((l[0]-l[2])/32)*Degree + math.Sin(float64(e.T)*0.05)*b.Bob
+	}
+
+	e.Vel.Y++
+	e.Pos = e.Pos.Add(e.Vel)
+	if b.Righting != 0 {
+		e.UpdateAngle(Lerp(e.Angle, e.TargetAngle, 1-b.Righting))
+	}
+	return wet
+}
+
+// Sink lets a dying entity go down, slowed by the water, and marks it dead
+// once it is off the bottom of the screen. The hull drags the surface down
+// with it while it goes under. It reports whether the entity is under
+// water.
+func (b *Buoyancy) Sink(e *Entity) bool {
+	e.UpdateAngle(Lerp(e.Angle, e.TargetAngle, 1-b.Capsize))
+	e.Pos.Y += e.Vel.Y
+	e.Vel.Y++
+
+	m := e.Image()
+	l := WaterRegion(e.Pos, m)
+	wet := e.Pos.Bottom(m) > l[1]
+	if wet {
+		e.Vel.Y *= b.Drag
+		if e.Pos.Y < l[1] {
+			SplashWater(e.Pos.CenterX(m), float64(m.W)/2, e.Vel.Y*b.Mass*0.15)
+		}
+	}
+
+	if e.Pos.Y >= H {
+		e.Dead = true
+	}
+	return wet
+}
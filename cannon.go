@@ -9,7 +9,7 @@ type Cannon struct {
 }
 
 func (c *Cannon) Init(pos Point, shipAngle float64, left, special bool) {
-	p := []*Image{LoadImage("kuti"), LoadImage("lokki2")}
+	p := []*Image{LoadImage("kuti"), LoadImage("erikoiskuti")}
 	i := []*Image{p[0].Copy(), p[1].Copy()}
 
 	c.Entity.Reset()
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"github.com/qeedquan/go-media/sdl"
+	"github.com/qeedquan/go-media/sdl/sdlimage/sdlcolor"
+)
+
+const ChargeTime = Fps * 3
+
+// Charge counts how long fire has been held since the last shot. Letting
+// go once it is ready fires a special round.
+type Charge struct {
+	Active bool
+	frames int
+	pos    Point
+}
+
+func (c *Charge) Reset() {
+	c.Active = false
+	c.frames = 0
+	c.pos = Point{100, 24}
+}
+
+func (c *Charge) Start() {
+	c.Active = true
+	c.frames = 0
+}
+
+func (c *Charge) Stop() {
+	c.Active = false
+	c.frames = 0
+}
+
+func (c *Charge) Ready() bool {
+	return c.Active && c.frames > ChargeTime
+}
+
+func (c *Charge) Update() {
+	if c.Active && c.frames <= ChargeTime {
+		c.frames++
+	}
+}
+
+func (c *Charge) Draw() {
+	if !c.Active {
+		return
+	}
+
+	const w, h = 60, 6
+	x, y := int32(c.pos.X), int32(c.pos.Y)
+
+	screen.SetDrawColor(sdlcolor.Black)
+	screen.FillRect(sdl.Rect{x, y, w, h})
+
+	color := sdl.Color{255, 127, 0, 255}
+	if c.Ready() {
+		color = sdl.Color{230, 30, 20, 255}
+	}
+	fill := int32((w - 2) * Min(c.frames, ChargeTime) / ChargeTime)
+	screen.SetDrawColor(color)
+	screen.FillRect(sdl.Rect{x + 1, y + 1, fill, h - 2})
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"time"
+
+	"github.com/qeedquan/go-media/sdl"
+)
+
+// Tick is how long one simulation step lasts. Everything that moves does
+// so in units per tick, whatever rate the screen is drawn at.
+const Tick = time.Second / Fps
+
+// MaxCatchUp bounds how many steps a slow frame runs at once. Anything
+// beyond that is dropped so a stall slows the game down instead of making
+// it jump ahead.
+const MaxCatchUp = 5
+
+// blend is how far drawing is from the previous simulation step to the
+// latest one, so moving things can be drawn in between.
+var blend = 1.0
+
+// Clock hands out the real time that passes in fixed simulation steps.
+type Clock struct {
+	last time.Time
+	acc  time.Duration
+}
+
+// Start resets the clock with one step due right away.
+func (c *Clock) Start() {
+	c.last = time.Now()
+	c.acc = Tick
+}
+
+// Advance returns how many steps are due since the last call.
+func (c *Clock) Advance() int {
+	now := time.Now()
+	c.acc += now.Sub(c.last)
+	c.last = now
+
+	n := int(c.acc / Tick)
+	if n > MaxCatchUp {
+		c.acc = 0
+		return MaxCatchUp
+	}
+	c.acc -= time.Duration(n) * Tick
+	return n
+}
+
+// Blend is how far the clock is into the next step, from 0 to 1.
+func (c *Clock) Blend() float64 {
+	return float64(c.acc) / float64(Tick)
+}
+
+// FramePeriod is how long a drawn frame lasts at the configured rate.
+func FramePeriod() time.Duration {
+	if config.FrameRate <= 0 {
+		return Tick
+	}
+	return time.Second / time.Duration(config.FrameRate)
+}
+
+// WaitFrame hands events to handle until the next frame is due or the
+// state quits, sleeping on the event queue in between.
+func (s *State) WaitFrame(period time.Duration, handle func(sdl.Event)) {
+	now := time.Now()
+	s.next = s.next.Add(period)
+	if s.next.Before(now) {
+		// fell behind, don't rush the frames after this to make up
+		s.next = now
+	}
+
+	s.NextFrame = false
+	for !s.NextFrame {
+		d := time.Until(s.next)
+		if d <= 0 {
+			ev := PollEvent()
+			if ev == nil {
+				break
+			}
+			handle(ev)
+			continue
+		}
+
+		ev := WaitEvent(d)
+		if ev != nil {
+			handle(ev)
+		}
+	}
+}
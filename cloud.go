@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"math/rand"
+	"time"
 )
 
 type Cloud struct {
@@ -13,6 +14,7 @@ type Cloud struct {
 
 type Skies struct {
 	clouds []Cloud
+	rng    *rand.Rand
 	t      int
 }
 
@@ -20,9 +22,9 @@ var (
 	skies Skies
 )
 
-func (c *Cloud) Init() {
-	c.image = LoadImage(fmt.Sprint("cloud", rand.Intn(4)+1))
-	c.pos = Point{W, rand.Float64() * 70}
+func (c *Cloud) Init(rng *rand.Rand) {
+	c.image = LoadImage(fmt.Sprint("cloud", rng.Intn(4)+1))
+	c.pos = Point{W, rng.Float64() * 70}
 	c.vel = Point{-1, 0}
 }
 
@@ -31,13 +33,17 @@ func (c *Cloud) Update() {
 }
 
 func (c *Cloud) Draw() {
-	c.image.Blit(c.pos)
+	c.image.Blit(c.pos.Add(c.vel.Scale(blend - 1)))
 }
 
 func InitClouds() {
 	skies.Init()
 }
 
+func ResetClouds(seed int64) {
+	skies.Reset(seed)
+}
+
 func UpdateClouds() {
 	skies.Update()
 }
@@ -50,13 +56,20 @@ func (s *Skies) Init() {
 	for i := 1; i <= 4; i++ {
 		LoadImage(fmt.Sprint("cloud", i))
 	}
+	s.Reset(time.Now().UnixNano())
+}
+
+func (s *Skies) Reset(seed int64) {
+	s.clouds = s.clouds[:0]
+	s.rng = rand.New(rand.NewSource(seed))
+	s.t = 0
 }
 
 func (s *Skies) Update() {
 	if s.t%150 == 0 {
 		var c Cloud
 
-		c.Init()
+		c.Init(s.rng)
 		s.clouds = append(s.clouds, c)
 	}
 
@@ -1,34 +1,36 @@
 package main
 
-import "image"
+import (
+	"image"
 
+	"github.com/qeedquan/go-media/sdl"
+)
+
+// collisionRect is the screen area covered by the entity's mask, which for
+// a tilted entity is centered on the sprite like Image.Blit draws it.
 func collisionRect(e *Entity) image.Rectangle {
-	x, y := int(e.Pos.X), int(e.Pos.Y)
-	b := e.Image().Alpha.Bounds()
-	return image.Rect(x, y, x+b.Dx(), y+b.Dy())
+	return e.Image().Mask().Bounds().Add(collisionOrigin(e))
+}
+
+func collisionOrigin(e *Entity) image.Point {
+	return image.Pt(int(e.Pos.X), int(e.Pos.Y))
 }
 
 func Collision(p1, p2 *Entity) bool {
-	r1 := collisionRect(p1)
-	r2 := collisionRect(p2)
-	r := r1.Intersect(r2)
-	if r.Dx() == 0 || r.Dy() == 0 {
+	r := collisionRect(p1).Intersect(collisionRect(p2))
+	if r.Empty() {
 		return false
 	}
 
-	m1 := p1.Image().Alpha
-	m2 := p2.Image().Alpha
-
-	x1 := r.Min.X - r1.Min.X
-	y1 := r.Min.Y - r1.Min.Y
-	x2 := r.Min.X - r2.Min.X
-	y2 := r.Min.Y - r2.Min.Y
+	m1 := p1.Image().Mask()
+	m2 := p2.Image().Mask()
+	o1 := collisionOrigin(p1)
+	o2 := collisionOrigin(p2)
 
-	w, h := r.Dx(), r.Dy()
-	for y := 0; y < h; y++ {
-		for x := 0; x < w; x++ {
-			c1 := m1.AlphaAt(x+x1, y+y1)
-			c2 := m2.AlphaAt(x+x2, y+y2)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			c1 := m1.AlphaAt(x-o1.X, y-o1.Y)
+			c2 := m2.AlphaAt(x-o2.X, y-o2.Y)
 			if c1.A&c2.A != 0 {
 				return true
 			}
@@ -37,3 +39,33 @@ func Collision(p1, p2 *Entity) bool {
 
 	return false
 }
+
+// DrawCollision outlines the entity's collision bounds and mask so they can
+// be compared with the sprite underneath, blended between the last two
+// steps the way Entity.Draw places the sprite.
+func DrawCollision(e *Entity, blend float64) {
+	p, angle := e.Blended(blend)
+	m := e.Image().Masks.At(angle)
+	o := image.Pt(int(p.X), int(p.Y))
+	r := m.Bounds().Add(o)
+
+	screen.SetDrawColor(sdl.Color{0, 255, 0, 255})
+	screen.DrawLine(r.Min.X, r.Min.Y, r.Max.X-1, r.Min.Y)
+	screen.DrawLine(r.Min.X, r.Max.Y-1, r.Max.X-1, r.Max.Y-1)
+	screen.DrawLine(r.Min.X, r.Min.Y, r.Min.X, r.Max.Y-1)
+	screen.DrawLine(r.Max.X-1, r.Min.Y, r.Max.X-1, r.Max.Y-1)
+
+	solid := func(x, y int) bool {
+		return m.AlphaAt(x, y).A != 0
+	}
+
+	screen.SetDrawColor(sdl.Color{255, 0, 255, 255})
+	b := m.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if solid(x, y) && !(solid(x-1, y) && solid(x+1, y) && solid(x, y-1) && solid(x, y+1)) {
+				screen.DrawLine(o.X+x, o.Y+y, o.X+x, o.Y+y)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+// lineRecorder is a headless renderer that keeps the bounds of the lines
+// drawn.
+type lineRecorder struct {
+	nullRenderer
+	bounds image.Rectangle
+}
+
+func (l *lineRecorder) DrawLine(x1, y1, x2, y2 int) {
+	l.bounds = l.bounds.Union(image.Rect(x1, y1, x2+1, y2+1))
+}
+
+// TestDrawCollisionBlends checks the hitbox overlay sits where the sprite
+// is drawn between two steps rather than where the last step left it.
+func TestDrawCollisionBlends(t *testing.T) {
+	InitHeadless()
+	defer func(s Renderer) { screen = s }(screen)
+
+	img := LoadImage("hai")
+	tests := []struct {
+		blend float64
+		pos   Point
+		angle float64
+	}{
+		{1, Point{110, 60}, 30},
+		{0, Point{90, 40}, 0},
+		{0.25, Point{95, 45}, 7.5},
+		{0.5, Point{100, 50}, 15},
+	}
+	for _, tt := range tests {
+		var e Entity
+		e.Images = []*Image{img.Copy()}
+		e.Pos = Point{90, 40}
+		e.Save()
+		e.Pos = Point{110, 60}
+		e.UpdateAngle(30)
+
+		l := &lineRecorder{}
+		screen = l
+		DrawCollision(&e, tt.blend)
+
+		o := image.Pt(int(tt.pos.X), int(tt.pos.Y))
+		want := img.Masks.At(tt.angle).Bounds().Add(o)
+		if l.bounds != want {
+			t.Errorf("blend %v: outlined %v, want %v", tt.blend, l.bounds, want)
+		}
+	}
+}
@@ -18,11 +18,19 @@ type Config struct {
 	Resource      string
 	Name          string
 	Profile       string
+	Simulate      int
+	Seed          int64
+	Replay        string
+	Endless       bool
+	Map           string
 	Fullscreen    bool
 	Invincibility bool
 	Sound         bool
 	Music         bool
 	Particles     bool
+	Hitboxes      bool
+	FrameRate     int
+	Bindings      Bindings
 }
 
 func (c *Config) Parse() {
@@ -43,17 +51,30 @@ func (c *Config) Parse() {
 	flag.StringVar(&c.Dir, "c", userDir, "config directory")
 	flag.StringVar(&c.Resource, "r", "data", "resource directory")
 	flag.StringVar(&c.Profile, "p", "", "turn on profiling and output to file")
+	flag.Int64Var(&c.Seed, "seed", 0, "random seed for game sessions (0 picks one from the clock)")
+	flag.StringVar(&c.Replay, "replay", "", "play back a recorded replay file")
+	flag.IntVar(&c.Simulate, "sim", 0, "simulate this many frames without a display and print the score")
+	flag.BoolVar(&c.Endless, "e", false, "simulate endless mode")
+	flag.StringVar(&c.Map, "map", "", "custom campaign map file")
 	flag.BoolVar(&fullscreen, "f", false, "fullscreen")
 	flag.BoolVar(&invincible, "i", false, "invincible")
 	flag.BoolVar(&noSound, "ns", false, "no sound")
 	flag.BoolVar(&noMusic, "nm", false, "no music")
 	flag.BoolVar(&noParticles, "np", false, "no particles")
+	flag.BoolVar(&c.Hitboxes, "hitbox", false, "draw collision masks over sprites")
+	flag.IntVar(&c.FrameRate, "hz", 60, "frames drawn per second, the game itself always runs at 30 steps per second")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: funnyboat [options]")
+		fmt.Fprintln(flag.CommandLine.Output(), "       funnyboat [options] scores command [arguments]")
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
 	c.Particles = true
 	c.Sound = true
 	c.Music = true
 	c.Name = "Funny Boat"
+	c.Bindings = DefaultBindings()
 	c.Load()
 
 	flag.Visit(func(f *flag.Flag) {
@@ -72,6 +93,17 @@ func (c *Config) Parse() {
 	})
 }
 
+// SimulateMap is the map a headless simulation runs on.
+func (c *Config) SimulateMap() string {
+	switch {
+	case c.Map != "":
+		return c.Map
+	case c.Endless:
+		return "endless"
+	}
+	return "story"
+}
+
 func (c *Config) Path() (string, error) {
 	err := os.MkdirAll(c.Dir, 0755)
 	if err != nil && !os.IsExist(err) {
@@ -136,6 +168,8 @@ func (c *Config) Load() {
 			c.Name = tokens[1]
 		case "sound":
 			c.Sound, _ = strconv.ParseBool(tokens[1])
+		case "bind":
+			c.loadBinding(tokens[1:])
 		}
 	}
 }
@@ -169,6 +203,13 @@ func (c *Config) Save() {
 	fmt.Fprintf(w, "music\t%v\n", c.Music)
 	fmt.Fprintf(w, "name\t%v\n", c.Name)
 	fmt.Fprintf(w, "sound\t%v\n", c.Sound)
+	for a, list := range c.Bindings {
+		fmt.Fprintf(w, "bind\t%v", Action(a))
+		for _, b := range list {
+			fmt.Fprintf(w, "\t%v", b)
+		}
+		fmt.Fprintf(w, "\n")
+	}
 
 	flushErr := w.Flush()
 	closeErr := f.Close()
@@ -178,3 +219,22 @@ func (c *Config) Save() {
 		err = closeErr
 	}
 }
+
+func (c *Config) loadBinding(tokens []string) {
+	a, err := ParseAction(tokens[0])
+	if err != nil {
+		log.Print(err)
+		return
+	}
+
+	var list []Binding
+	for _, t := range tokens[1:] {
+		b, err := ParseBinding(t)
+		if err != nil {
+			log.Print(err)
+			continue
+		}
+		list = append(list, b)
+	}
+	c.Bindings[a] = list
+}
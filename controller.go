@@ -0,0 +1,121 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/qeedquan/go-media/sdl"
+)
+
+// StickDeadzone is how far an analog stick has to be pushed before it
+// counts as a direction.
+const StickDeadzone = 16384
+
+type Pad struct {
+	*sdl.GameController
+	axes map[sdl.GameControllerAxis]int
+}
+
+type Controllers struct {
+	pads map[sdl.JoystickID]*Pad
+}
+
+var (
+	controllers Controllers
+)
+
+// InitControllers loads the controller layouts shipped in the resource
+// directory and any the user keeps in the config directory. Controllers
+// already plugged in show up as device added events like hot-plugged ones.
+func InitControllers() {
+	controllers.Init()
+}
+
+// PollEvent is sdl.PollEvent that also keeps track of controllers being
+// plugged in and out.
+func PollEvent() sdl.Event {
+	ev := sdl.PollEvent()
+	controllers.Event(ev)
+	return ev
+}
+
+// WaitEvent is PollEvent that sleeps up to the timeout for an event to
+// arrive, returning nil if none does.
+func WaitEvent(timeout time.Duration) sdl.Event {
+	ms := int((timeout + time.Millisecond - 1) / time.Millisecond)
+	ev := sdl.WaitEventTimeout(ms)
+	controllers.Event(ev)
+	return ev
+}
+
+func (c *Controllers) Init() {
+	c.pads = make(map[sdl.JoystickID]*Pad)
+
+	log.SetPrefix("controller: ")
+	files := []string{filepath.Join(config.Resource, "gamecontrollerdb.txt")}
+	if path, err := config.Path(); err == nil {
+		files = append(files, filepath.Join(path, "gamecontrollerdb.txt"))
+	}
+
+	for _, name := range files {
+		if _, err := os.Stat(name); err != nil {
+			continue
+		}
+		n, err := sdl.GameControllerAddMappingsFromFile(name)
+		if err != nil {
+			log.Print(err)
+		} else {
+			log.Printf("loaded %v mappings from %q", n, name)
+		}
+	}
+}
+
+func (c *Controllers) Event(ev sdl.Event) {
+	switch ev := ev.(type) {
+	case sdl.ControllerDeviceAddedEvent:
+		log.SetPrefix("controller: ")
+		gc, err := sdl.GameControllerOpen(int(ev.Which))
+		if err != nil {
+			log.Print(err)
+			return
+		}
+		id := gc.Joystick().InstanceID()
+		c.pads[id] = &Pad{
+			GameController: gc,
+			axes:           make(map[sdl.GameControllerAxis]int),
+		}
+		log.Printf("connected %q", gc.Name())
+
+	case sdl.ControllerDeviceRemovedEvent:
+		p := c.pads[ev.Which]
+		if p == nil {
+			return
+		}
+		log.SetPrefix("controller: ")
+		log.Printf("disconnected %q", p.Name())
+		p.Close()
+		delete(c.pads, ev.Which)
+	}
+}
+
+// Stick turns an axis event into -1, 0 or 1 and also returns the direction
+// the axis was in before, so a stick pushed over can be treated like a key
+// that went down once and came back up once.
+func (c *Controllers) Stick(ev sdl.ControllerAxisEvent) (prev, dir int) {
+	switch {
+	case ev.Value < -StickDeadzone:
+		dir = -1
+	case ev.Value > StickDeadzone:
+		dir = 1
+	}
+
+	p := c.pads[ev.Which]
+	if p == nil {
+		return
+	}
+	prev = p.axes[ev.Axis]
+	p.axes[ev.Axis] = dir
+	return
+}
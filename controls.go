@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qeedquan/go-media/sdl"
+)
+
+// Controls is the options submenu for rebinding actions. Picking an
+// action waits for the next key, button, stick or mouse press and binds
+// it, replacing the old binding from the same kind of device.
+type Controls struct {
+	Selector
+}
+
+func (c *Controls) Init() {
+	c.Selector.Init(smallFont, c.event)
+}
+
+func (c *Controls) Run() {
+	c.refresh()
+	c.Selector.Run(c.menu, 0)
+}
+
+func (c *Controls) event(ev sdl.Event, actions []ActionEvent) {
+	if c.grab {
+		if ev, ok := ev.(sdl.KeyDownEvent); ok && ev.Sym == sdl.K_ESCAPE {
+			c.grab = false
+		} else if b, ok := BindingFor(ev); ok {
+			config.Bindings.Bind(Action(c.cursor), b)
+			c.grab = false
+		}
+		c.refresh()
+		return
+	}
+
+	for _, a := range actions {
+		if !a.Down || a.Action != ActionConfirm {
+			continue
+		}
+		if c.cursor == int(NumActions) {
+			config.Bindings = DefaultBindings()
+		} else {
+			c.grab = true
+		}
+		break
+	}
+	c.refresh()
+}
+
+func (c *Controls) refresh() {
+	c.menu = c.menu[:0]
+	for a := Action(0); a < NumActions; a++ {
+		if c.grab && c.cursor == int(a) {
+			c.menu = append(c.menu, fmt.Sprint(a.Title(), ": press a key or button"))
+			continue
+		}
+
+		var titles []string
+		for _, b := range config.Bindings[a] {
+			titles = append(titles, b.Title())
+		}
+		c.menu = append(c.menu, fmt.Sprint(a.Title(), ": ", strings.Join(titles, ", ")))
+	}
+	c.menu = append(c.menu, "Reset to defaults")
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"image"
+
+	"github.com/qeedquan/go-media/sdl"
+	"github.com/qeedquan/go-media/sdl/sdlgfx"
+	"github.com/qeedquan/go-media/sdl/sdlimage"
+	"github.com/qeedquan/go-media/sdl/sdlttf"
+)
+
+type Display struct {
+	*sdl.Window
+	*sdl.Renderer
+	vertices []sdl.Vertex
+	indices  []int32
+}
+
+// displayTexture knows its size so batches can address it in texture
+// coordinates.
+type displayTexture struct {
+	*sdl.Texture
+	w, h int
+}
+
+func newDisplay(w, h int, wflag sdl.WindowFlags) (*Display, error) {
+	window, renderer, err := sdl.CreateWindowAndRenderer(w, h, wflag)
+	if err != nil {
+		return nil, err
+	}
+	return &Display{Window: window, Renderer: renderer}, nil
+}
+
+func (d *Display) NewTexture(w, h int) (Texture, error) {
+	texture, err := d.Renderer.CreateTexture(sdl.PIXELFORMAT_ARGB8888, sdl.TEXTUREACCESS_TARGET, w, h)
+	if err != nil {
+		return nil, err
+	}
+	texture.SetBlendMode(sdl.BLENDMODE_BLEND)
+	return displayTexture{texture, w, h}, nil
+}
+
+func (d *Display) LoadTexture(img image.Image) (Texture, error) {
+	texture, err := sdlimage.LoadTextureImage(d.Renderer, img)
+	if err != nil {
+		return nil, err
+	}
+	texture.SetBlendMode(sdl.BLENDMODE_BLEND)
+	b := img.Bounds()
+	return displayTexture{texture, b.Dx(), b.Dy()}, nil
+}
+
+func (d *Display) SetTarget(t Texture) error {
+	var texture *sdl.Texture
+	if t != nil {
+		texture = t.(displayTexture).Texture
+	}
+	return d.Renderer.SetTarget(texture)
+}
+
+func (d *Display) SetDrawColor(c sdl.Color) {
+	d.Renderer.SetDrawColor(c)
+}
+
+func (d *Display) Clear() {
+	d.Renderer.Clear()
+}
+
+func (d *Display) DrawLine(x1, y1, x2, y2 int) {
+	d.Renderer.DrawLine(x1, y1, x2, y2)
+}
+
+func (d *Display) FillRect(r sdl.Rect) {
+	d.Renderer.FillRect(&r)
+}
+
+func (d *Display) FilledEllipse(x, y, rx, ry int, c sdl.Color) {
+	sdlgfx.FilledEllipse(d.Renderer, x, y, rx, ry, c)
+}
+
+func (d *Display) Blit(t Texture, dst sdl.Rect, angle float64) {
+	d.Renderer.CopyEx(t.(displayTexture).Texture, nil, &dst, angle, nil, sdl.FLIP_NONE)
+}
+
+// BlitBatch draws the sprites from the same texture with a single
+// geometry call, two triangles a sprite with its opacity in the vertex
+// colors.
+func (d *Display) BlitBatch(t Texture, sprites []Sprite) {
+	if len(sprites) == 0 {
+		return
+	}
+
+	texture := t.(displayTexture)
+	tw, th := float32(texture.w), float32(texture.h)
+	d.vertices = d.vertices[:0]
+	d.indices = d.indices[:0]
+	for i := range sprites {
+		s := &sprites[i]
+		c := sdl.Color{255, 255, 255, s.Alpha}
+		x0, y0 := float32(s.Dst.X), float32(s.Dst.Y)
+		x1, y1 := float32(s.Dst.X+s.Dst.W), float32(s.Dst.Y+s.Dst.H)
+		u0, v0 := float32(s.Src.X)/tw, float32(s.Src.Y)/th
+		u1, v1 := float32(s.Src.X+s.Src.W)/tw, float32(s.Src.Y+s.Src.H)/th
+
+		n := int32(len(d.vertices))
+		d.vertices = append(d.vertices,
+			sdl.Vertex{Position: sdl.FPoint{X: x0, Y: y0}, Color: c, TexCoord: sdl.FPoint{X: u0, Y: v0}},
+			sdl.Vertex{Position: sdl.FPoint{X: x1, Y: y0}, Color: c, TexCoord: sdl.FPoint{X: u1, Y: v0}},
+			sdl.Vertex{Position: sdl.FPoint{X: x0, Y: y1}, Color: c, TexCoord: sdl.FPoint{X: u0, Y: v1}},
+			sdl.Vertex{Position: sdl.FPoint{X: x1, Y: y1}, Color: c, TexCoord: sdl.FPoint{X: u1, Y: v1}},
+		)
+		d.indices = append(d.indices, n, n+1, n+2, n+2, n+1, n+3)
+	}
+	d.Renderer.Geometry(texture.Texture, d.vertices, d.indices)
+}
+
+// FillGeometry draws untextured triangles shaded between the colors of
+// their vertices, blending them with what is underneath.
+func (d *Display) FillGeometry(vertices []sdl.Vertex, indices []int32) {
+	d.Renderer.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	d.Renderer.Geometry(nil, vertices, indices)
+	d.Renderer.SetDrawBlendMode(sdl.BLENDMODE_NONE)
+}
+
+// RenderText rasterizes a single line of text into a new texture the
+// size of the line.
+func (d *Display) RenderText(font *sdlttf.Font, c sdl.Color, text string) (Texture, int, int, error) {
+	r, err := font.RenderUTF8BlendedEx(surface, text, c)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	err = surface.Lock()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	w, h := Min(int(r.W), W), Min(int(r.H), H)
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	s := surface.Pixels()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*W*4 + x*4
+			j := img.PixOffset(x, y)
+			img.Pix[j] = s[i+2]
+			img.Pix[j+1] = s[i]
+			img.Pix[j+2] = s[i+1]
+			img.Pix[j+3] = s[i+3]
+		}
+	}
+	surface.Unlock()
+
+	t, err := d.LoadTexture(img)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return t, w, h, nil
+}
+
+func (d *Display) Present() {
+	d.Renderer.Present()
+}
+
+func (t displayTexture) SetAlphaMod(a uint8) {
+	t.Texture.SetAlphaMod(a)
+}
+
+func (t displayTexture) Destroy() {
+	t.Texture.Destroy()
+}
@@ -17,6 +17,10 @@ type Entity struct {
 	Angle       float64
 	TargetAngle float64
 	T           int
+
+	prev      Point
+	prevAngle float64
+	saved     bool
 }
 
 func (e *Entity) Reset() {
@@ -31,15 +35,38 @@ func (e *Entity) Reset() {
 	e.Angle = 0
 	e.TargetAngle = 0
 	e.T = 0
+	e.saved = false
 }
 
 func (e *Entity) Image() *Image {
 	return e.Images[e.Frame]
 }
 
+// Save remembers where the entity is before a simulation step, so drawing
+// can blend from there to where the step moves it.
+func (e *Entity) Save() {
+	e.prev = e.Pos
+	e.prevAngle = e.Angle
+	e.saved = true
+}
+
 func (e *Entity) Draw() {
 	m := e.Image()
-	m.Blit(e.Pos)
+	if !e.saved {
+		m.Blit(e.Pos)
+		return
+	}
+
+	m.BlitAngle(e.Blended(blend))
+}
+
+// Blended returns where the entity is drawn between the last two steps.
+func (e *Entity) Blended(blend float64) (Point, float64) {
+	if !e.saved {
+		return e.Pos, e.Image().Angle
+	}
+	p := Point{Lerp(e.Pos.X, e.prev.X, blend), Lerp(e.Pos.Y, e.prev.Y, blend)}
+	return p, Lerp(e.Angle, e.prevAngle, blend)
 }
 
 func (e *Entity) UpdateAngle(angle float64) {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"math/rand"
+	"time"
 
 	"github.com/qeedquan/go-media/sdl"
 	"github.com/qeedquan/go-media/sdl/sdlimage/sdlcolor"
@@ -12,25 +13,34 @@ type Game struct {
 
 	health Health
 	score  Score
+	charge Charge
 	level  Level
 
+	banners Banners
+
 	ensemble      Ensemble
 	player        Steamboat
 	playerCannons []Cannon
 	enemyCannons  []Cannon
-	mines         []Mine
-	seagulls      []Seagull
-	sharks        []Shark
-	powerups      []Powerup
-	pirates       []Pirate
-	titanic       *Titanic
+	actors        []Actor
+	unique        map[string]Actor
+	broadphase    Broadphase
+
+	lastShot int
+	t        int
 
-	lastShot     int
-	spacePressed int
-	t            int
+	seed     int64
+	rng      *rand.Rand
+	input    Input
+	frame    int
+	replay   *Replay
+	playback *Replay
+	assists  Assists
 
 	paused   bool
 	gameOver string
+
+	clock Clock
 }
 
 func (g *Game) Init() {
@@ -39,67 +49,113 @@ func (g *Game) Init() {
 	g.ensemble.Init()
 }
 
-func (g *Game) Reset(endless bool) {
+func (g *Game) Reset(m *Map, seed int64) {
 	g.clear()
 
+	g.seed = seed
+	g.rng = rand.New(rand.NewSource(seed))
+
+	ResetWater()
+	ResetClouds(seed)
+
 	g.State.Reset()
 	g.health.Reset()
 	g.score.Reset()
-	g.level.Reset(endless)
+	g.charge.Reset()
+	g.level.Reset(m, g.rng)
+	g.banners.Reset()
+	g.ensemble.Reset(g.rng)
 	g.player.Reset()
 
 	g.paused = false
 	g.gameOver = ""
 
+	g.input = 0
+	g.frame = 0
+	g.assists = 0
+	if config.Invincibility {
+		g.assists |= AssistInvincible
+	}
+	g.replay = &Replay{Seed: seed, Map: m.ID, Assists: g.assists}
+	g.playback = nil
+
 	g.lastShot = 0
-	g.spacePressed = 0
 	g.t = 0
 }
 
-func (g *Game) Run(endless bool) int {
-	g.Reset(endless)
-
-	for !g.Done {
-		g.draw()
-		g.update()
-		g.event()
-	}
+func (g *Game) Run(m *Map, seed int64) int {
+	g.Reset(m, seed)
+	g.loop()
+	return g.score.Value
+}
 
+// Play runs a recorded session, feeding its inputs back through update
+// instead of the keyboard.
+func (g *Game) Play(m *Map, r *Replay) int {
+	g.Rewind(m, r)
+	g.loop()
 	return g.score.Value
 }
 
-func (g *Game) draw() {
-	g.State.Draw()
-	g.health.Draw()
-	g.score.Draw()
+// Rewind resets the game to the start of a recorded session on the map
+// it was recorded on, with the assists it was recorded with whatever the
+// options say.
+func (g *Game) Rewind(m *Map, r *Replay) {
+	g.Reset(m, r.Seed)
+	g.playback = r
+	g.assists = r.Assists
+	g.replay.Assists = r.Assists
+}
 
-	for i := range g.powerups {
-		p := &g.powerups[i]
-		p.Draw()
-	}
+// loop steps the simulation at the fixed tick rate and draws in between at
+// the frame rate, blending moving things between the last two steps.
+func (g *Game) loop() {
+	period := FramePeriod()
+	g.clock.Start()
+	for !g.Done {
+		for n := g.clock.Advance(); n > 0 && !g.Done; n-- {
+			g.update()
+		}
 
-	for i := range g.sharks {
-		s := &g.sharks[i]
-		s.Draw()
+		blend = g.clock.Blend()
+		g.draw()
+		g.WaitFrame(period, g.event)
 	}
+	blend = 1
+}
 
-	for i := range g.pirates {
-		p := &g.pirates[i]
-		p.Draw()
+// Result describes the session since the last Reset as a high score entry.
+func (g *Game) Result() Rank {
+	now := time.Now()
+	return Rank{
+		Name:       config.Name,
+		Value:      g.score.Value,
+		Mode:       g.replay.Map,
+		Date:       &now,
+		Frames:     g.t,
+		Phase:      g.level.Phase(),
+		Seed:       g.seed,
+		Invincible: g.assists&AssistInvincible != 0,
 	}
+}
 
-	for i := range g.seagulls {
-		s := &g.seagulls[i]
-		s.Draw()
-	}
+// Replay returns the inputs recorded since the last Reset.
+func (g *Game) Replay() *Replay {
+	return g.replay
+}
 
-	if g.titanic != nil {
-		g.titanic.Draw()
-	}
+func (g *Game) Step() {
+	g.update()
+}
 
-	for i := range g.mines {
-		m := &g.mines[i]
-		m.Draw()
+func (g *Game) draw() {
+	g.State.Draw()
+	g.health.Draw()
+	g.score.Draw()
+	g.charge.Draw()
+
+	for _, a := range g.actors {
+		a.Draw()
 	}
 
 	g.drawCannons(g.playerCannons)
@@ -108,26 +164,41 @@ func (g *Game) draw() {
 	g.player.Draw()
 	g.ensemble.Draw()
 
+	if config.Hitboxes {
+		g.drawHitboxes()
+	}
+
+	// messages float over the waves, so a light shadow keeps them
+	// readable when the sea is rough
 	if g.paused {
-		paused := "Paused"
-		tw, th, _ := bigFont.SizeUTF8(paused)
-		blitText(bigFont, (W-tw)/2, (H-th*4)/2, sdlcolor.Black, paused)
+		const paused = "Paused"
+		_, th := MeasureText(bigFont, paused)
+		DrawText(Big().Aligned(AlignCenter).Shadow(sdlcolor.White), W/2, (H-th*4)/2, paused)
 	}
 
 	if g.gameOver != "" {
-		tw, th, _ := bigFont.SizeUTF8(g.gameOver)
-		blitText(bigFont, (W-tw)/2, (H-th)/2, sdlcolor.Black, g.gameOver)
+		_, th := MeasureText(bigFont, g.gameOver)
+		DrawText(Big().Aligned(AlignCenter).Shadow(sdlcolor.White), W/2, (H-th)/2, g.gameOver)
 	} else {
-		message, _ := g.level.Message()
-		if message != "" {
-			tw, th, _ := smallFont.SizeUTF8(message)
-			blitText(smallFont, (W-tw)/2, (H-th)/2, sdlcolor.Black, message)
-		}
+		g.banners.Draw()
 	}
 
 	screen.Present()
 }
 
+func (g *Game) drawHitboxes() {
+	for _, a := range g.actors {
+		DrawCollision(a.Body(), blend)
+	}
+	for i := range g.playerCannons {
+		DrawCollision(&g.playerCannons[i].Entity, blend)
+	}
+	for i := range g.enemyCannons {
+		DrawCollision(&g.enemyCannons[i].Entity, blend)
+	}
+	DrawCollision(&g.player.Entity, blend)
+}
+
 func (g *Game) drawCannons(cannons []Cannon) {
 	for i := range cannons {
 		c := &cannons[i]
@@ -136,6 +207,8 @@ func (g *Game) drawCannons(cannons []Cannon) {
 }
 
 func (g *Game) update() {
+	g.save()
+	g.handleInput()
 	if g.paused {
 		return
 	}
@@ -143,10 +216,12 @@ func (g *Game) update() {
 	if g.gameOver == "" {
 		g.spawn()
 	}
+	g.banners.Update()
 
-	g.updateEnemies()
+	g.updateActors()
 	g.player.Update()
 	g.health.Update()
+	g.charge.Update()
 
 	g.updateCannons(&g.playerCannons)
 	g.updateCannons(&g.enemyCannons)
@@ -154,7 +229,6 @@ func (g *Game) update() {
 	g.score.Update()
 	g.addEnvironmentEffects()
 	g.ensemble.Update()
-	g.updatePowerups()
 	g.State.Update()
 
 	if g.gameOver == "" {
@@ -173,6 +247,7 @@ func (g *Game) update() {
 
 		g.ensemble.Explosion(p)
 		g.ensemble.Debris(p)
+		SplashBlast(p)
 	case g.player.Dead:
 		g.gameOver = "Game Over"
 	}
@@ -181,131 +256,72 @@ func (g *Game) update() {
 	g.t++
 }
 
-func (g *Game) updateEnemies() {
-	for i := 0; i < len(g.mines); {
-		m := &g.mines[i]
-		m.Update()
-
-		remove := false
-		if m.Exploding {
-			if m.ExplodeFrames == 0 {
-				remove = true
-			}
-
-			x := m.Pos.CenterX(m.Image())
-			y := m.Pos.Y + float64(m.Image().W)/2
-			p := Point{x, y}
-			g.ensemble.Explosion(p)
-			g.ensemble.Debris(p)
-		}
-
-		if m.Pos.Right(m.Image()) < 0 {
-			remove = true
-		}
-
-		if remove {
-			m.Free()
-			l := len(g.mines) - 1
-			g.mines[i], g.mines = g.mines[l], g.mines[:l]
-		} else {
-			i++
+func (g *Game) handleInput() {
+	if g.playback != nil {
+		in, ok := g.playback.Input(g.frame)
+		if !ok {
+			g.Quit()
+			return
 		}
+		g.input = in
+	} else {
+		g.replay.Record(g.input)
 	}
+	g.frame++
 
-	for i := 0; i < len(g.sharks); {
-		s := &g.sharks[i]
-		s.Update()
-
-		m := s.Image()
-		if s.Dying {
-			p := Point{s.Pos.CenterX(m), s.Pos.CenterY(m)}
-			g.ensemble.Blood(p)
-		}
+	in := g.input
+	g.input &= inputHeld
 
-		if s.Pos.Right(m) < 0 || s.Dead {
-			s.Free()
-			l := len(g.sharks) - 1
-			g.sharks[i], g.sharks = g.sharks[l], g.sharks[:l]
-		} else {
-			i++
-		}
+	if in&InputPause != 0 {
+		g.paused = !g.paused
 	}
 
-	for i := 0; i < len(g.pirates); {
-		p := &g.pirates[i]
-		p.Update()
-		m := p.Image()
-
-		center := Point{p.Pos.CenterX(m), p.Pos.CenterY(m)}
-		if p.T%50 == 0 && !p.Dying {
-			var c Cannon
-
-			pos := Point{p.Pos.X, p.Pos.CenterY(m)}
-			c.Init(pos, g.player.Angle, true, false)
-			g.enemyCannons = append(g.enemyCannons, c)
+	if in&InputFire != 0 {
+		g.playerFire()
+	}
 
-			pt := p.Rotate(Point{0, 10}).Add(center)
-			for i := 0; i < 4; i++ {
-				g.ensemble.Fire(pt)
-			}
-		} else if p.Dying {
-			g.ensemble.Explosion(center)
-			g.ensemble.Wood(center)
-		}
+	if in&InputCharge == 0 && g.charge.Active && !g.paused {
+		g.playerRelease()
+	}
 
-		if p.Pos.Right(m) < 0 || p.Dead {
-			p.Free()
-			l := len(g.pirates) - 1
-			g.pirates[i], g.pirates = g.pirates[l], g.pirates[:l]
-		} else {
-			i++
-		}
+	if in&InputLeft == 0 {
+		g.player.MoveLeft(false)
+	}
+	if in&InputRight == 0 {
+		g.player.MoveRight(false)
 	}
 
-	if g.titanic != nil {
-		t := g.titanic
-		t.Update()
-
-		shoot := false
-		angle := 0.0
-		if !t.Dying {
-			switch t.T % 100 {
-			case 0:
-				angle = 50
-				shoot = true
-			case 50:
-				angle = 52.5
-				shoot = true
-			}
+	if !g.paused {
+		if in&InputLeft != 0 {
+			g.player.MoveLeft(true)
 		}
-
-		if shoot {
-			for i := 0; i < 3; i++ {
-				var c Cannon
-				pos := Point{t.Pos.X, t.Pos.CenterY(t.Image())}
-				c.Init(pos, g.player.Angle+float64(i-1)*10-angle, true, false)
-				g.enemyCannons = append(g.enemyCannons, c)
-			}
+		if in&InputRight != 0 {
+			g.player.MoveRight(true)
 		}
-
-		if t.Dead {
-			g.gameOver = "Congratulations!\nYou sunk Titanic!"
-			t.Free()
-			g.titanic = nil
+		if in&InputJump != 0 {
+			g.player.Jump()
 		}
 	}
+}
 
-	for i := 0; i < len(g.seagulls); {
-		s := &g.seagulls[i]
-		s.Update()
-		if s.Pos.Right(s.Image()) < 0 || s.Dead {
-			s.Free()
-			l := len(g.seagulls) - 1
-			g.seagulls[i], g.seagulls = g.seagulls[l], g.seagulls[:l]
-		} else {
-			i++
+// updateActors steps every actor and drops the ones that are gone,
+// keeping the rest in spawn order.
+func (g *Game) updateActors() {
+	n := 0
+	for i := 0; i < len(g.actors); i++ {
+		a := g.actors[i]
+		a.Update(g)
+		if a.Gone() {
+			a.Free()
+			continue
 		}
+		g.actors[n] = a
+		n++
 	}
+	for i := n; i < len(g.actors); i++ {
+		g.actors[i] = nil
+	}
+	g.actors = g.actors[:n]
 }
 
 func (g *Game) updateCannons(cannons *[]Cannon) {
@@ -318,18 +334,20 @@ func (g *Game) updateCannons(cannons *[]Cannon) {
 			g.ensemble.Trace(q)
 		}
 
-		if c.Special && (!c.Underwater || rand.Float64() > 0.6) {
+		if c.Special && (!c.Underwater || g.rng.Float64() > 0.6) {
 			p := c.Tail()
 			g.ensemble.Explosion(p)
+			SplashBlast(p)
 		}
 
 		undOld := c.Underwater
 		c.Update()
 		if c.Underwater && !undOld {
+			SplashWater(c.Pos.CenterX(c.Image()), 4, c.Vel.Y*0.3)
 			for i := 0; i < 5; i++ {
 				p := Point{
-					c.Pos.Right(c.Image()) - 4 + rand.Float64()*8,
-					c.Pos.Y + rand.Float64()*2,
+					c.Pos.Right(c.Image()) - 4 + g.rng.Float64()*8,
+					c.Pos.Y + g.rng.Float64()*2,
 				}
 				g.ensemble.Water(p)
 			}
@@ -353,13 +371,13 @@ func (g *Game) addEnvironmentEffects() {
 		g.player.Pos.CenterY(g.player.Image()),
 	}
 
-	p := g.player.Rotate(Point{5 + rand.Float64()*9, 0})
+	p := g.player.Rotate(Point{5 + g.rng.Float64()*9, 0})
 	p = p.Add(c)
 
-	q := g.player.Rotate(Point{19 + rand.Float64()*7, 5})
+	q := g.player.Rotate(Point{19 + g.rng.Float64()*7, 5})
 	q = q.Add(c)
 
-	if !(g.spacePressed != 0 && g.t > g.spacePressed+Fps*3) {
+	if !g.charge.Ready() {
 		g.ensemble.Steam(p)
 		g.ensemble.Steam(q)
 	}
@@ -367,23 +385,14 @@ func (g *Game) addEnvironmentEffects() {
 	if g.player.Dying && !g.player.Dead {
 		g.ensemble.Explosion(c)
 		g.ensemble.Debris(c)
-	}
-
-	if g.titanic != nil {
-		for i := 0; i < 4; i++ {
-			c := Point{
-				g.titanic.Pos.CenterX(g.titanic.Image()),
-				g.titanic.Pos.CenterY(g.titanic.Image()),
-			}
-			p := g.titanic.Rotate(Point{49 + rand.Float64()*9 + 28*float64(i), 25})
-			p = p.Add(c)
-			g.ensemble.Steam(p)
-		}
+		SplashBlast(c)
 	}
 
 	if g.player.Splash {
+		m := g.player.Image()
+		SplashWater(g.player.Pos.CenterX(m), float64(m.W)/2, 6)
 		for i := 0; i < 10; i++ {
-			r := rand.Float64()
+			r := g.rng.Float64()
 			x := Lerp(g.player.Pos.X, g.player.Pos.Right(g.player.Image()), r)
 			p := Point{x, WaterLevel(x)}
 			g.ensemble.Water(p)
@@ -391,112 +400,119 @@ func (g *Game) addEnvironmentEffects() {
 	}
 }
 
-func (g *Game) updatePowerups() {
-	for i := 0; i < len(g.powerups); {
-		p := &g.powerups[i]
-		p.Update()
-		if p.Picked {
-			l := len(g.powerups) - 1
-			g.powerups[i], g.powerups = g.powerups[l], g.powerups[:l]
-			p.Free()
-		} else {
-			i++
-		}
+// save remembers where everything was before a step for drawing.
+func (g *Game) save() {
+	g.player.Save()
+	for _, a := range g.actors {
+		a.Body().Save()
+	}
+	for i := range g.playerCannons {
+		g.playerCannons[i].Save()
+	}
+	for i := range g.enemyCannons {
+		g.enemyCannons[i].Save()
 	}
 }
 
-func (g *Game) event() {
-	g.NextFrame = false
-	for !g.NextFrame {
-		select {
-		case <-frame.C:
-			g.NextFrame = true
-		default:
-		}
+func (g *Game) event(ev sdl.Event) {
+	if _, ok := ev.(sdl.QuitEvent); ok {
+		g.Quit()
+		return
+	}
 
-		for {
-			ev := sdl.PollEvent()
-			if ev == nil {
-				break
-			}
-			switch ev := ev.(type) {
-			case sdl.QuitEvent:
-				g.Quit()
-
-			case sdl.KeyDownEvent:
-				switch ev.Sym {
-				case sdl.K_ESCAPE:
-					g.Quit()
-				case sdl.K_p, sdl.K_RETURN:
-					g.paused = !g.paused
-				case sdl.K_s:
-					Snapshot()
-				case sdl.K_SPACE:
-					g.playerFire()
-				}
+	actions := config.Bindings.Translate(ev)
+	for _, a := range actions {
+		if !a.Down {
+			continue
+		}
+		switch a.Action {
+		case ActionBack:
+			g.Quit()
+		case ActionScreenshot:
+			Snapshot()
+		}
+	}
 
-			case sdl.KeyUpEvent:
-				switch ev.Sym {
-				case sdl.K_LEFT:
-					g.player.MoveLeft(false)
-				case sdl.K_RIGHT:
-					g.player.MoveRight(false)
-				}
-			}
+	if g.playback != nil {
+		return
+	}
 
-			if !g.paused {
-				switch ev := ev.(type) {
-				case sdl.KeyDownEvent:
-					switch ev.Sym {
-					case sdl.K_LEFT:
-						g.player.MoveLeft(true)
-					case sdl.K_RIGHT:
-						g.player.MoveRight(true)
-					case sdl.K_UP:
-						g.player.Jump()
-					}
-				}
-			}
+	for _, a := range actions {
+		in := actionInputs[a.Action]
+		if in == 0 {
+			continue
+		}
+		if a.Down {
+			g.press(in)
+		} else {
+			g.release(in)
 		}
 	}
 }
 
-func (g *Game) spawn() {
-	s := g.level.Spawn()
+func (g *Game) press(in Input) {
+	// fire ignores key repeat so holding it charges a special round
+	// instead of firing again
+	if in == InputFire {
+		if g.input&InputCharge != 0 {
+			return
+		}
+		in |= InputCharge
+	}
+	g.input |= in
+}
 
-	if s&0x1 != 0 {
-		s := Shark{}
-		s.Init()
-		g.sharks = append(g.sharks, s)
+// release only clears held inputs, a jump or shot pressed and let go
+// between two frames still happens on the next one.
+func (g *Game) release(in Input) {
+	if in&InputFire != 0 {
+		in |= InputCharge
 	}
+	g.input &^= in & inputHeld
+}
 
-	if s&0x2 != 0 {
-		p := Pirate{}
-		p.Init()
-		g.pirates = append(g.pirates, p)
+func (g *Game) spawn() {
+	for _, ev := range g.level.Spawn() {
+		for i := 0; i < Max(ev.Count, 1); i++ {
+			g.spawnOne(ev, i)
+		}
 	}
 
-	if s&0x4 != 0 {
-		m := Mine{}
-		m.Init()
-		g.mines = append(g.mines, m)
+	ctx := BannerContext{
+		Name:  config.Name,
+		Score: g.score.Value,
+		Phase: g.level.Phase(),
+		Color: g.level.Color(),
+	}
+	for _, b := range g.level.Banners() {
+		g.banners.Push(b, ctx)
 	}
+}
 
-	if s&0x8 != 0 {
-		s := Seagull{}
-		s.Init()
-		g.seagulls = append(g.seagulls, s)
+func (g *Game) spawnOne(ev SpawnEvent, i int) {
+	kind := actorKinds[ev.Kind]
+	if kind == nil {
+		return
+	}
+	if kind.Unique {
+		if a := g.unique[ev.Kind]; a != nil && !a.Gone() {
+			return
+		}
 	}
 
-	if s&0x10 != 0 && g.titanic == nil {
-		g.titanic = &Titanic{}
-		g.titanic.Init()
+	a := kind.New(g, ev)
+	if kind.Unique {
+		g.unique[ev.Kind] = a
 	}
+	g.actors = append(g.actors, a)
 
-	if s&0x20 != 0 {
-		p := Powerup{}
-		p.Init()
-		g.powerups = append(g.powerups, p)
+	e := a.Body()
+	if ev.Pos != nil {
+		e.Pos = *ev.Pos
+	}
+	e.Pos = e.Pos.Add(ev.Spacing.Scale(float64(i)))
+	if ev.Vel != nil {
+		e.Vel = *ev.Vel
 	}
 }
 
@@ -512,16 +528,37 @@ func (g *Game) playerFire() {
 		g.playerCannons = append(g.playerCannons, c)
 
 		g.lastShot = 0
-		g.spacePressed = g.t
+		g.charge.Start()
+	}
+}
+
+func (g *Game) playerRelease() {
+	if g.charge.Ready() && !g.player.Dying {
+		var c Cannon
+
+		m := g.player.Image()
+		pos := Point{g.player.Pos.Right(m), g.player.Pos.Y}
+		c.Init(pos, g.player.Angle, false, true)
+		g.playerCannons = append(g.playerCannons, c)
+
+		center := Point{g.player.Pos.CenterX(m), g.player.Pos.CenterY(m)}
+		pt := g.player.Rotate(Point{42, 10}).Add(center)
+		for i := 0; i < 30; i++ {
+			jitter := Point{g.rng.Float64()*8 - 4, g.rng.Float64()*6 - 3}
+			g.ensemble.Fire(pt.Add(jitter))
+		}
+
+		g.lastShot = 0
 	}
+	g.charge.Stop()
 }
 
 func (g *Game) damagePlayer() {
 	p := &g.player
-	if !config.Invincibility {
+	if g.assists&AssistInvincible == 0 {
 		g.health.Damage()
 		for i := 0; i < 10; i++ {
-			pt := Point{rand.Float64() * 26, rand.Float64() * 10}
+			pt := Point{g.rng.Float64() * 26, g.rng.Float64() * 10}
 			ct := Point{p.Pos.CenterX(p.Image()), p.Pos.CenterY(p.Image())}
 			pt = pt.Add(ct)
 			g.ensemble.Debris(pt)
@@ -530,144 +567,87 @@ func (g *Game) damagePlayer() {
 	p.Blinks += 12
 }
 
+// Kinds of bodies entered into the broadphase. Actors are found by their
+// index in the actor list.
+const (
+	bodyPlayer = iota
+	bodyActor
+	bodyCannon
+)
+
 func (g *Game) checkCollision() {
 	p := &g.player
+	b := &g.broadphase
 
-	for i := range g.powerups {
-		pw := &g.powerups[i]
-		if !pw.Fading && !p.Dying && Collision(&p.Entity, &pw.Entity) {
-			g.health.Add()
-			pw.Pickup()
-		}
+	b.Reset()
+	b.Add(LayerPlayer, bodyPlayer, 0, &p.Entity)
+	for i, a := range g.actors {
+		b.Add(a.Layer(), bodyActor, i, a.Body())
 	}
-
-	for i := range g.mines {
-		m := &g.mines[i]
-		if !m.Exploding && !p.Dying && Collision(&p.Entity, &m.Entity) {
-			g.damagePlayer()
-			m.Explode()
-		}
+	for i := range g.playerCannons {
+		b.Add(LayerPlayerShot, bodyCannon, i, &g.playerCannons[i].Entity)
 	}
-
-	for i := range g.sharks {
-		s := &g.sharks[i]
-		if !s.Dying && !p.Dying && Collision(&p.Entity, &s.Entity) {
-			g.damagePlayer()
-			s.Damage(1)
-		}
+	for i := range g.enemyCannons {
+		b.Add(LayerEnemyShot, bodyCannon, i, &g.enemyCannons[i].Entity)
 	}
 
-	g.checkCollisionPlayerCannon(&g.playerCannons)
-	g.checkCollisionPlayerCannon(&g.enemyCannons)
-
-	for i := range g.sharks {
-		s := &g.sharks[i]
-		for j := 0; j < len(g.playerCannons); {
-			c := &g.playerCannons[j]
-			if !s.Dying && Collision(&s.Entity, &c.Entity) {
-				g.score.Add(15)
-				s.Damage(1)
-				s.Vel.X += c.Vel.X * 0.6
-				s.Vel.Y += c.Vel.Y * 0.4
-				if !c.Special {
-					c.Free()
-					l := len(g.playerCannons) - 1
-					g.playerCannons[j], g.playerCannons = g.playerCannons[l], g.playerCannons[:l]
-				}
-				break
-			} else {
-				j++
+	for _, l := range []Layer{LayerPickup, LayerEnemy} {
+		for _, pr := range b.Pairs(LayerPlayer, l) {
+			if !p.Dying {
+				g.actors[pr.B.Index].Touch(g)
 			}
 		}
 	}
 
-	for i := range g.seagulls {
-		s := &g.seagulls[i]
-		for j := 0; j < len(g.playerCannons); {
-			c := &g.playerCannons[j]
-			if !s.Dying && Collision(&s.Entity, &c.Entity) {
-				g.score.Add(75)
-				s.Damage(1)
-				s.Vel.X += c.Vel.X * 0.6
-				s.Vel.Y += c.Vel.Y * 0.4
-				if !c.Special {
-					c.Free()
-					l := len(g.playerCannons) - 1
-					g.playerCannons[j], g.playerCannons = g.playerCannons[l], g.playerCannons[:l]
-				}
-				break
-			} else {
-				j++
+	// the player's own cannonballs can come down on it too
+	for _, l := range []Layer{LayerPlayerShot, LayerEnemyShot} {
+		for _, pr := range b.Pairs(LayerPlayer, l) {
+			c := g.cannon(pr.B)
+			if !p.Dying && Collision(&p.Entity, &c.Entity) {
+				g.damagePlayer()
+				c.Dead = true
 			}
 		}
 	}
 
-	for i := range g.pirates {
-		pe := &g.pirates[i]
-		for j := 0; j < len(g.playerCannons); {
-			c := &g.playerCannons[j]
-			if !pe.Dying && Collision(&pe.Entity, &c.Entity) {
-				snd := LoadSound("poks")
-				snd.Play(0)
-
-				g.score.Add(25)
-				pe.Damage(1)
-
-				for i := 0; i < 6; i++ {
-					pt := Point{
-						p.Pos.CenterX(p.Image()),
-						p.Pos.CenterY(p.Image()),
-					}
-					pt.X += rand.Float64() * 15
-					pt.Y += rand.Float64()*30 - 10
-					g.ensemble.Wood(pt)
-				}
+	// an actor takes at most one cannonball a frame, and only special
+	// cannonballs go on to hit something else
+	var last *Body
+	for _, pr := range b.Pairs(LayerEnemy, LayerPlayerShot) {
+		c := g.cannon(pr.B)
+		if pr.A == last || c.Dead || pr.A.Entity.Dying {
+			continue
+		}
+		if !Collision(pr.A.Entity, &c.Entity) {
+			continue
+		}
 
-				if !c.Special {
-					c.Free()
-					l := len(g.playerCannons) - 1
-					g.playerCannons[j], g.playerCannons = g.playerCannons[l], g.playerCannons[:l]
-				}
-				break
-			} else {
-				j++
-			}
+		a := g.actors[pr.A.Index]
+		if !a.Hit(g, c) {
+			continue
+		}
+		last = pr.A
+		g.score.Add(a.Value(c))
+		if !c.Special {
+			c.Dead = true
 		}
 	}
 
-	if g.titanic != nil {
-		t := g.titanic
-		for j := 0; j < len(g.playerCannons); {
-			c := &g.playerCannons[j]
-			if !t.Dying && Collision(&t.Entity, &c.Entity) {
-				snd := LoadSound("poks")
-				snd.Play(0)
-
-				if c.Special {
-					t.Damage(12)
-					g.score.Add(100)
-				} else {
-					t.Damage(1)
-					g.score.Add(7)
-				}
+	g.removeDeadCannons(&g.playerCannons)
+	g.removeDeadCannons(&g.enemyCannons)
+}
 
-				c.Free()
-				l := len(g.playerCannons) - 1
-				g.playerCannons[j], g.playerCannons = g.playerCannons[l], g.playerCannons[:l]
-				break
-			} else {
-				j++
-			}
-		}
+func (g *Game) cannon(b *Body) *Cannon {
+	if b.Layer == LayerPlayerShot {
+		return &g.playerCannons[b.Index]
 	}
+	return &g.enemyCannons[b.Index]
 }
 
-func (g *Game) checkCollisionPlayerCannon(cannons *[]Cannon) {
-	p := &g.player
+func (g *Game) removeDeadCannons(cannons *[]Cannon) {
 	for i := 0; i < len(*cannons); {
 		c := &(*cannons)[i]
-		if !p.Dying && Collision(&p.Entity, &c.Entity) {
-			g.damagePlayer()
+		if c.Dead {
 			c.Free()
 			l := len(*cannons) - 1
 			(*cannons)[i], *cannons = (*cannons)[l], (*cannons)[:l]
@@ -691,41 +671,20 @@ func (g *Game) clear() {
 		c.Free()
 	}
 
-	for i := range g.pirates {
-		p := &g.pirates[i]
-		p.Free()
-	}
-
-	for i := range g.mines {
-		m := &g.mines[i]
-		m.Free()
-	}
-
-	for i := range g.seagulls {
-		s := &g.seagulls[i]
-		s.Free()
-	}
-
-	for i := range g.sharks {
-		s := &g.sharks[i]
-		s.Free()
-	}
-
-	for i := range g.powerups {
-		p := &g.powerups[i]
-		p.Free()
-	}
-
-	if g.titanic != nil {
-		g.titanic.Free()
-		g.titanic = nil
+	for i, a := range g.actors {
+		a.Free()
+		g.actors[i] = nil
 	}
 
 	g.playerCannons = g.playerCannons[:0]
 	g.enemyCannons = g.enemyCannons[:0]
-	g.pirates = g.pirates[:0]
-	g.mines = g.mines[:0]
-	g.seagulls = g.seagulls[:0]
-	g.sharks = g.sharks[:0]
-	g.powerups = g.powerups[:0]
+	g.actors = g.actors[:0]
+	g.unique = make(map[string]Actor)
+}
+
+func NewSeed() int64 {
+	if config.Seed != 0 {
+		return config.Seed
+	}
+	return time.Now().UnixNano()
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"log"
+
+	"github.com/qeedquan/go-media/sdl"
+	"github.com/qeedquan/go-media/sdl/sdlttf"
+)
+
+type nullRenderer struct{}
+
+type nullTexture struct{}
+
+func (nullRenderer) NewTexture(w, h int) (Texture, error)         { return nullTexture{}, nil }
+func (nullRenderer) LoadTexture(img image.Image) (Texture, error) { return nullTexture{}, nil }
+func (nullRenderer) SetTarget(t Texture) error                    { return nil }
+func (nullRenderer) SetDrawColor(c sdl.Color)                     {}
+func (nullRenderer) Clear()                                       {}
+func (nullRenderer) DrawLine(x1, y1, x2, y2 int)                  {}
+func (nullRenderer) FillRect(r sdl.Rect)                          {}
+func (nullRenderer) FilledEllipse(x, y, rx, ry int, c sdl.Color)  {}
+func (nullRenderer) Blit(t Texture, dst sdl.Rect, angle float64)  {}
+func (nullRenderer) BlitBatch(t Texture, sprites []Sprite)        {}
+func (nullRenderer) FillGeometry(v []sdl.Vertex, i []int32)       {}
+func (nullRenderer) Present()                                     {}
+
+func (nullRenderer) RenderText(font *sdlttf.Font, c sdl.Color, s string) (Texture, int, int, error) {
+	return nullTexture{}, 0, 0, nil
+}
+
+func (nullTexture) SetAlphaMod(a uint8) {}
+func (nullTexture) Destroy()            {}
+
+func InitHeadless() {
+	screen = nullRenderer{}
+	config.Sound = false
+	config.Music = false
+
+	InitWater()
+	InitClouds()
+}
+
+// Simulate plays a game on the given map without a window for at most the
+// given number of frames, stopping early if the player sinks, and prints
+// the result. When a replay file is configured its seed, map and inputs are
+// used instead.
+func Simulate(frames int, mapID string) {
+	InitHeadless()
+
+	var game Game
+	game.Init()
+	if config.Replay != "" {
+		log.SetPrefix("replay: ")
+		replay, err := LoadReplay(config.Replay)
+		if err != nil {
+			log.Fatal(err)
+		}
+		m, err := LoadMap(replay.Map)
+		if err != nil {
+			log.Fatal(err)
+		}
+		game.Rewind(m, replay)
+	} else {
+		log.SetPrefix("map: ")
+		m, err := LoadMap(mapID)
+		if err != nil {
+			log.Fatal(err)
+		}
+		game.Reset(m, NewSeed())
+	}
+
+	n := 0
+	for ; n < frames && !game.Done && !game.player.Dead; n++ {
+		game.Step()
+	}
+
+	fmt.Printf("seed: %v frames: %v score: %v\n", game.seed, n, game.score.Value)
+}
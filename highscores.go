@@ -1,217 +1,411 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"log"
-	"os"
-	"path/filepath"
-	"sort"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/qeedquan/go-media/sdl"
 	"github.com/qeedquan/go-media/sdl/sdlimage/sdlcolor"
 )
 
-type Rank struct {
-	Name  string
-	Value int
+// Highscores browses the tables of the story, endless and the chosen map,
+// each with a tab for honest and one for assisted runs. Runs with cheats
+// on go into the assisted table, which never pushes out an honest entry.
+// Above the table sit the name and date filters; the table shows MaxRanks
+// entries a page and confirming an entry shows the details of the run,
+// from where its replay can be watched. A run that makes it into a table
+// is put under the cursor and the player is asked for a name first.
+type Highscores struct {
+	State
+	game    *Game
+	tabs    []scoreTab
+	tab     int
+	shown   []int
+	cursor  int
+	filter  NameEntry
+	editing bool
+	dates   int
+	details *Rank
+	status  string
+	entry   *Rank
+	naming  bool
+	name    NameEntry
+	t       int
 }
 
-type RankSlice []Rank
+type scoreTab struct {
+	title    string
+	scores   *ScoreTable
+	assisted bool
+}
 
-func (s RankSlice) Len() int           { return len(s) }
-func (s RankSlice) Less(i, j int) bool { return s[i].Value > s[j].Value }
-func (s RankSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+// The rows above the entries, which start at 0.
+const (
+	rowName = -2
+	rowDate = -1
+)
 
-type Highscores struct {
-	State
-	ranks    []Rank
-	title    string
-	filename string
+// dateRanges are what the date filter cycles through, counted in days
+// back from the start of today. Undated entries only show for any time.
+var dateRanges = []struct {
+	label string
+	days  int
+}{
+	{"Any time", 0},
+	{"Today", 1},
+	{"Last 7 days", 7},
+	{"Last 30 days", 30},
+	{"Last year", 365},
 }
 
-var dummyScores = []Rank{
-	{"Funny Boat", 2000},
-	{"Hectigo", 1500},
-	{"JDruid", 1000},
-	{"Pekuja", 750},
-	{"Pirate", 500},
-	{"Shark", 400},
-	{"Seagull", 300},
-	{"Naval Mine", 200},
-	{"Cannonball", 100},
-	{"Puffy the Cloud", 50},
+// timeNow tells the date filter what day it is.
+var timeNow = time.Now
+
+var (
+	AssistedColor  = sdl.Color{120, 60, 160, 255}
+	HighlightColor = sdl.Color{255, 127, 0, 255}
+)
+
+// Init sets up the screen to watch replays in the given game.
+func (h *Highscores) Init(game *Game) {
+	h.State.Init()
+	h.game = game
 }
 
-func (h *Highscores) Reset(endless bool, newScore int) {
+// Reset loads the tables and files the entry of a run that just ended on
+// the map, if there is one. Runs that make it into a table get their
+// replay saved alongside.
+func (h *Highscores) Reset(m *Map, entry *Rank, replay *Replay) {
 	h.State.Reset()
 
-	h.title = "Story Mode"
-	h.filename = "scores"
-	if endless {
-		h.title = "Endless Mode"
-		h.filename = "endless_scores"
+	tables := []*ScoreTable{LoadScores("story"), LoadScores("endless")}
+	titles := []string{"Story", "Endless"}
+	if m.ID != "story" && m.ID != "endless" {
+		tables = append(tables, LoadScores(m.ID))
+		titles = append(titles, m.Name)
 	}
 
-	h.Load()
-	h.Update(newScore)
+	h.tabs = h.tabs[:0]
+	for i, t := range tables {
+		h.tabs = append(h.tabs, scoreTab{titles[i], t, false}, scoreTab{titles[i], t, true})
+		if t.Mode == m.ID {
+			h.tab = 2 * i
+		}
+	}
+
+	h.filter.Reset("")
+	h.editing = false
+	h.dates = 0
+	h.details = nil
+	h.status = ""
+	h.entry = nil
+	h.naming = false
+	h.cursor = 0
+	h.t = 0
+
+	if entry != nil {
+		if entry.Assisted() {
+			h.tab++
+		}
+		scores := h.tabs[h.tab].scores
+		h.entry = scores.Add(*entry)
+		if h.entry != nil {
+			if replay != nil {
+				h.entry.Replay = replay.Save()
+			}
+			h.naming = true
+			h.name.Reset(entry.Name)
+		}
+		scores.Save()
+	}
+	h.refilter()
 }
 
-func (h *Highscores) Load() {
-	var filename string
-	var err error
+// rename files the entered name with the new entry and remembers it for
+// the next run.
+func (h *Highscores) rename() {
+	h.naming = false
+	h.entry.Name = h.name.Name()
+	config.Name = h.entry.Name
+	config.Save()
+	h.tabs[h.tab].scores.Save()
+	h.refilter()
+}
 
-	h.ranks = h.ranks[:0]
+func (h *Highscores) table() RankSlice {
+	tab := &h.tabs[h.tab]
+	return *tab.scores.Table(tab.assisted)
+}
 
-	log.SetPrefix("scores: ")
-	defer func() {
-		if err != nil {
-			log.Print("load failure: ", err)
-			h.ranks = append(h.ranks[:0], dummyScores...)
-		} else {
-			log.Printf("load %q", filename)
-		}
+// refilter collects the entries of the tab that pass the filters, keeping
+// the cursor on the new entry while it is shown.
+func (h *Highscores) refilter() {
+	name := strings.ToLower(h.filter.Text())
+	var since time.Time
+	if days := dateRanges[h.dates].days; days > 0 {
+		y, m, d := timeNow().Date()
+		since = time.Date(y, m, d-days+1, 0, 0, 0, 0, time.Local)
+	}
 
-		if len(h.ranks) >= MaxRanks {
-			h.ranks = h.ranks[:MaxRanks]
+	table := h.table()
+	h.shown = h.shown[:0]
+	for i := range table {
+		r := &table[i]
+		switch {
+		case name != "" && !strings.Contains(strings.ToLower(r.Name), name):
+		case !since.IsZero() && (r.Date == nil || r.Date.Before(since)):
+		default:
+			if r == h.entry && h.naming {
+				h.cursor = len(h.shown)
+			}
+			h.shown = append(h.shown, i)
 		}
-	}()
-
-	path, err := config.Path()
-	if err != nil {
-		return
 	}
+	h.cursor = Max(rowName, Min(h.cursor, len(h.shown)-1))
+}
 
-	filename = filepath.Join(path, h.filename)
-	f, err := os.Open(filename)
-	if err != nil {
-		return
+func (h *Highscores) Run(m *Map, entry *Rank, replay *Replay) {
+	h.Reset(m, entry, replay)
+	for !h.Done {
+		h.draw()
+		h.State.Update()
+		h.WaitFrame(Tick, h.event)
 	}
-	defer f.Close()
+}
 
-	var line [2]string
+func (h *Highscores) draw() {
+	h.State.Draw()
 
-	s := bufio.NewScanner(f)
-loop:
-	for n := 0; n < MaxRanks; n++ {
-		for i := 0; i < 2; i++ {
-			if !s.Scan() {
-				break loop
-			}
-			line[i] = s.Text()
-		}
+	tab := &h.tabs[h.tab]
+	DrawText(Big().Aligned(AlignCenter), W/2, 10, tab.title)
 
-		value, err := strconv.Atoi(line[1])
-		if err != nil {
-			continue
-		}
-		name := strings.TrimSpace(line[0])
-		h.ranks = append(h.ranks, Rank{name, value})
+	// the assisted tables are set apart by their heading and color
+	style := Small()
+	heading := "< Unassisted runs >"
+	if tab.assisted {
+		style = style.Colored(AssistedColor)
+		heading = "< Assisted runs (invincible) >"
+	}
+	top := 10 + bigFont.Height()
+	DrawText(style.Aligned(AlignCenter), W/2, top, heading)
+	top += smallFont.Height() + 4
+
+	if h.details != nil {
+		h.drawDetails(style, top)
+	} else {
+		h.drawTable(style, top)
 	}
 
-	sort.Stable(RankSlice(h.ranks))
-}
+	hint := ""
+	switch {
+	case h.naming:
+		hint = "Type or pick letters with up and down, then confirm"
+	case h.editing:
+		hint = "Type a name to look for, then confirm"
+	case h.status != "":
+		hint = h.status
+	}
+	if hint != "" {
+		_, hh := MeasureText(smallFont, hint)
+		DrawText(Small().Aligned(AlignCenter).Shadow(sdlcolor.White), W/2, H-hh-4, hint)
+	}
 
-func (h *Highscores) Save() {
-	var filename string
-	var err error
+	screen.Present()
+	h.t++
+}
 
-	log.SetPrefix("scores: ")
-	defer func() {
-		if err != nil {
-			log.Print("save error: ", err)
-		} else {
-			log.Printf("saved to %q", filename)
+func (h *Highscores) drawTable(style TextStyle, top int) {
+	th := smallFont.Height()
+	row := func(i int) TextStyle {
+		if i == h.cursor {
+			return style.Colored(HighlightColor)
 		}
-	}()
+		return style
+	}
 
-	path, err := config.Path()
-	if err != nil {
-		return
+	filter := h.filter.Text()
+	if h.editing {
+		before, after := h.filter.Split()
+		filter = before + after
+		h.drawCaret(10, top, "Name: "+before)
+	} else if filter == "" {
+		filter = "anyone"
 	}
+	DrawText(row(rowName), 10, top, "Name: "+filter)
+	DrawText(row(rowDate), 10, top+th, fmt.Sprintf("Date: < %v >", dateRanges[h.dates].label))
+	top += 2*th + 4
 
-	filename = filepath.Join(path, h.filename)
-	f, err := os.Create(filename)
-	if err != nil {
+	table := h.table()
+	if len(h.shown) == 0 {
+		DrawText(style.Aligned(AlignCenter), W/2, top, "No runs yet")
 		return
 	}
 
-	w := bufio.NewWriter(f)
-	for _, r := range h.ranks {
-		fmt.Fprintf(w, "%v\n%v\n", r.Name, r.Value)
+	page, pages := h.page()
+	for n := 0; n < MaxRanks && page*MaxRanks+n < len(h.shown); n++ {
+		i := page*MaxRanks + n
+		r := &table[h.shown[i]]
+		y := top + n*th
+		rs := row(i)
+
+		label := fmt.Sprintf("%v. %v", h.shown[i]+1, r.Name)
+		if r == h.entry && h.naming {
+			before, after := h.name.Split()
+			label = fmt.Sprintf("%v. %v", h.shown[i]+1, before)
+			h.drawCaret(10, y, label)
+			label += after
+		}
+		DrawText(rs, 10, y, label)
+		DrawText(rs.Aligned(AlignRight), W-10, y, fmt.Sprint(r.Value))
 	}
-	flushErr := w.Flush()
-	closeErr := f.Close()
 
-	err = flushErr
-	if err == nil {
-		err = closeErr
+	if pages > 1 && !h.naming {
+		y := top + MaxRanks*th
+		DrawText(style.Aligned(AlignRight), W-10, y, fmt.Sprintf("Page %v/%v", page+1, pages))
 	}
 }
 
-func (h *Highscores) Update(newScore int) {
-	if newScore < 0 || (len(h.ranks) > 0 && newScore < h.ranks[len(h.ranks)-1].Value) {
+// page returns the page of MaxRanks entries the cursor is on and how many
+// pages the shown entries fill. The filter rows show the first page.
+func (h *Highscores) page() (page, pages int) {
+	return Max(h.cursor, 0) / MaxRanks, (len(h.shown) + MaxRanks - 1) / MaxRanks
+}
+
+// drawCaret blinks the text cursor after the given text.
+func (h *Highscores) drawCaret(x, y int, before string) {
+	if h.t/8%2 != 0 {
 		return
 	}
-	h.ranks = append(h.ranks, Rank{config.Name, newScore})
-	sort.Stable(RankSlice(h.ranks))
-	if len(h.ranks) >= MaxRanks {
-		h.ranks = h.ranks[:MaxRanks]
-	}
-	h.Save()
+	w, th := MeasureText(smallFont, before)
+	screen.SetDrawColor(HighlightColor)
+	screen.FillRect(sdl.Rect{int32(x + w), int32(y), 2, int32(th)})
 }
 
-func (h *Highscores) Run(endless bool, newScore int) {
-	h.Reset(endless, newScore)
-	for !h.Done {
-		h.draw()
-		h.State.Update()
-		h.event()
+func (h *Highscores) drawDetails(style TextStyle, top int) {
+	th := smallFont.Height()
+	for i, l := range rankDetails(h.details) {
+		y := top + i*th
+		DrawText(style, 10, y, l[0])
+		DrawText(style.Aligned(AlignRight), W-10, y, l[1])
 	}
 }
 
-func (h *Highscores) draw() {
-	h.State.Draw()
-
-	tw, _, _ := bigFont.SizeUTF8(h.title)
-	blitText(bigFont, (W-tw)/2, 10, sdlcolor.Black, fmt.Sprint(h.title))
+// rankDetails returns the labelled lines the details of an entry show.
+func rankDetails(r *Rank) [][2]string {
+	date := "unknown"
+	if r.Date != nil {
+		date = r.Date.Local().Format("2006-01-02 15:04")
+	}
+	phase, duration := "unknown", "unknown"
+	if r.Phase > 0 {
+		phase = fmt.Sprint(r.Phase)
+	}
+	if r.Frames > 0 {
+		duration = r.Clock()
+	}
+	replay := "none"
+	if r.Replay != "" {
+		replay = "confirm to watch"
+	}
 
-	for i, r := range h.ranks {
-		_, th, _ := smallFont.SizeUTF8(r.Name)
-		x := 10
-		y := 50 + i*th
-		blitText(smallFont, x, y, sdlcolor.Black, fmt.Sprintf("%v. %v", i+1, r.Name))
+	return [][2]string{
+		{"Name", r.Name},
+		{"Score", fmt.Sprint(r.Value)},
+		{"Date", date},
+		{"Phase reached", phase},
+		{"Duration", duration},
+		{"Replay", replay},
+	}
+}
 
-		value := fmt.Sprint(r.Value)
-		tw, _, _ := smallFont.SizeUTF8(value)
-		x = W - tw - 10
-		blitText(smallFont, x, y, sdlcolor.Black, fmt.Sprint(value))
+func (h *Highscores) event(ev sdl.Event) {
+	_, quit := ev.(sdl.QuitEvent)
+	switch {
+	case h.naming:
+		h.name.Event(ev)
+		if h.name.Done || quit {
+			h.rename()
+		}
+	case h.editing:
+		h.filter.Event(ev)
+		h.editing = !h.filter.Done
+		h.refilter()
+	default:
+		for _, a := range config.Bindings.Translate(ev) {
+			if a.Down {
+				quit = h.action(a.Action) || quit
+			}
+		}
 	}
 
-	screen.Present()
+	if quit {
+		h.Quit()
+	}
 }
 
-func (h *Highscores) event() {
-	h.NextFrame = false
-	for !h.NextFrame {
-		select {
-		case <-frame.C:
-			h.NextFrame = true
-		default:
+// action handles an action outside of entering text, reporting whether
+// it leaves the screen.
+func (h *Highscores) action(a Action) bool {
+	if h.details != nil {
+		switch a {
+		case ActionBack:
+			h.details = nil
+			h.status = ""
+		case ActionConfirm:
+			h.watch()
 		}
+		return false
+	}
 
-		for {
-			ev := sdl.PollEvent()
-			if ev == nil {
-				break
-			}
-			switch ev.(type) {
-			case sdl.QuitEvent, sdl.KeyDownEvent:
-				h.Save()
-				h.Quit()
-			}
+	switch a {
+	case ActionBack:
+		return true
+	case ActionMoveUp:
+		h.cursor = Max(h.cursor-1, rowName)
+	case ActionMoveDown:
+		h.cursor = Min(h.cursor+1, len(h.shown)-1)
+	case ActionMoveLeft, ActionMoveRight:
+		dir := 1
+		if a == ActionMoveLeft {
+			dir = -1
+		}
+		if h.cursor == rowDate {
+			h.dates = (h.dates + dir + len(dateRanges)) % len(dateRanges)
+		} else {
+			h.tab = (h.tab + dir + len(h.tabs)) % len(h.tabs)
+			h.cursor = Min(h.cursor, 0)
+		}
+		h.refilter()
+	case ActionConfirm:
+		switch {
+		case h.cursor == rowName:
+			h.filter.Reset(h.filter.Text())
+			h.editing = true
+		case h.cursor == rowDate:
+			h.dates = (h.dates + 1) % len(dateRanges)
+			h.refilter()
+		default:
+			table := h.table()
+			h.details = &table[h.shown[h.cursor]]
 		}
 	}
+	return false
+}
+
+// watch plays back the replay of the entry shown in detail.
+func (h *Highscores) watch() {
+	if h.details.Replay == "" {
+		return
+	}
+
+	h.status = ""
+	err := WatchReplay(h.game, h.details.Replay)
+	if err != nil {
+		h.status = "Cannot play the replay"
+		log.SetPrefix("scores: ")
+		log.Print(err)
+	}
+	h.State.Reset()
 }
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// textureCounter is a headless renderer that counts the textures alive.
+type textureCounter struct {
+	nullRenderer
+	textures int
+}
+
+type countedTexture struct {
+	nullTexture
+	c *textureCounter
+}
+
+func (c *textureCounter) NewTexture(w, h int) (Texture, error) {
+	c.textures++
+	return countedTexture{c: c}, nil
+}
+
+func (t countedTexture) Destroy() {
+	t.c.textures--
+}
+
+// TestWatchReplayReusesGame watches a replay from the details of an entry
+// a few times and checks watching again leaves no more textures alive.
+func TestWatchReplayReusesGame(t *testing.T) {
+	InitHeadless()
+	scoresDir(t)
+	defer func(s Renderer) { screen = s }(screen)
+	c := &textureCounter{}
+	screen = c
+
+	replay := &Replay{Seed: 1, Map: "story", Inputs: make([]Input, 3)}
+	filename := replay.Save()
+	if filename == "" {
+		t.Fatal("replay not saved")
+	}
+
+	var game Game
+	var h Highscores
+	h.Init(&game)
+	game.Init()
+
+	alive := 0
+	for i := 0; i < 4; i++ {
+		h.details = &Rank{Name: "Pekuja", Value: 750, Replay: filename}
+		h.watch()
+		if h.status != "" {
+			t.Fatalf("watch %v: %v", i, h.status)
+		}
+		if i == 0 {
+			alive = c.textures
+		} else if c.textures != alive {
+			t.Errorf("watch %v left %v textures alive, the first %v", i, c.textures, alive)
+		}
+	}
+	if game.frame != len(replay.Inputs) {
+		t.Errorf("played %v frames, the replay has %v", game.frame, len(replay.Inputs))
+	}
+}
+
+// scoreScreen opens the scores on a scratch table holding the given
+// entries, with the date filter counting back from midday of 2026-03-15.
+func scoreScreen(t *testing.T, ranks []Rank) *Highscores {
+	t.Helper()
+	scoresDir(t)
+	prev := timeNow
+	t.Cleanup(func() { timeNow = prev })
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.Local)
+	timeNow = func() time.Time { return now }
+
+	table := &ScoreTable{Mode: "test", Ranks: ranks}
+	h := &Highscores{tabs: []scoreTab{{"Test", table, false}, {"Test", table, true}}}
+	h.refilter()
+	return h
+}
+
+// datedRanks returns entries with dates around 2026-03-15, from today
+// back past a year and one with no date at all.
+func datedRanks() []Rank {
+	at := func(y int, m time.Month, d, hour, min int) *time.Time {
+		t := time.Date(y, m, d, hour, min, 0, 0, time.Local)
+		return &t
+	}
+	return []Rank{
+		{Name: "Pekuja", Value: 900, Date: at(2026, 3, 15, 8, 0)},
+		{Name: "Hectigo", Value: 800, Date: at(2026, 3, 12, 20, 0)},
+		{Name: "JDruid", Value: 700, Date: at(2026, 2, 23, 10, 0)},
+		{Name: "Old PEKUJA", Value: 600, Date: at(2025, 8, 27, 10, 0)},
+		{Name: "Shark", Value: 500, Date: at(2024, 3, 1, 10, 0)},
+		{Name: "Pirate", Value: 400},
+		{Name: "Seagull", Value: 300, Date: at(2026, 3, 9, 0, 0)},
+		{Name: "Naval Mine", Value: 200, Date: at(2026, 3, 8, 23, 59)},
+	}
+}
+
+// shownNames returns the names of the entries passing the filters.
+func shownNames(h *Highscores) []string {
+	table := h.table()
+	var names []string
+	for _, i := range h.shown {
+		names = append(names, table[i].Name)
+	}
+	return names
+}
+
+func TestHighscoresRefilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		dates int
+		want  []string
+	}{
+		{"", 0, []string{"Pekuja", "Hectigo", "JDruid", "Old PEKUJA", "Shark", "Pirate", "Seagull", "Naval Mine"}},
+		{"pek", 0, []string{"Pekuja", "Old PEKUJA"}},
+		{"PEKUJA", 0, []string{"Pekuja", "Old PEKUJA"}},
+		{"nobody", 0, nil},
+		{"", 1, []string{"Pekuja"}},
+		{"", 2, []string{"Pekuja", "Hectigo", "Seagull"}},
+		{"", 3, []string{"Pekuja", "Hectigo", "JDruid", "Seagull", "Naval Mine"}},
+		{"", 4, []string{"Pekuja", "Hectigo", "JDruid", "Old PEKUJA", "Seagull", "Naval Mine"}},
+		{"pek", 3, []string{"Pekuja"}},
+		{"pek", 4, []string{"Pekuja", "Old PEKUJA"}},
+	}
+	for _, tt := range tests {
+		h := scoreScreen(t, datedRanks())
+		h.filter.Reset(tt.name)
+		h.dates = tt.dates
+		h.refilter()
+		got := shownNames(h)
+		if !sameNames(got, tt.want) {
+			t.Errorf("%q, %v: shows %q, want %q", tt.name, dateRanges[tt.dates].label, got, tt.want)
+		}
+	}
+}
+
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestHighscoresCursor(t *testing.T) {
+	h := scoreScreen(t, datedRanks())
+	h.tabs[1].scores.Assisted = []Rank{{Name: "Cannonball", Value: 100}, {Name: "Puffy the Cloud", Value: 50}}
+	filter := func(name string) func() {
+		return func() {
+			h.filter.Reset(name)
+			h.refilter()
+		}
+	}
+	act := func(a Action, n int) func() {
+		return func() {
+			for i := 0; i < n; i++ {
+				if h.action(a) {
+					t.Fatalf("%v left the screen", a)
+				}
+			}
+		}
+	}
+
+	steps := []struct {
+		name   string
+		do     func()
+		cursor int
+		dates  int
+		tab    int
+	}{
+		{"up to the date filter", act(ActionMoveUp, 1), rowDate, 0, 0},
+		{"up to the name filter", act(ActionMoveUp, 1), rowName, 0, 0},
+		{"up past the top", act(ActionMoveUp, 3), rowName, 0, 0},
+		{"back to the entries", act(ActionMoveDown, 2), 0, 0, 0},
+		{"down past the last", act(ActionMoveDown, 20), 7, 0, 0},
+		{"filter under the cursor", filter("pek"), 1, 0, 0},
+		{"filter out everything", filter("nobody"), rowDate, 0, 0},
+		{"down with no entries", act(ActionMoveDown, 1), rowDate, 0, 0},
+		{"cycle dates right", act(ActionMoveRight, 2), rowDate, 2, 0},
+		{"cycle dates left", act(ActionMoveLeft, 3), rowDate, 4, 0},
+		{"clear the filters", func() { h.dates = 0; filter("")() }, rowDate, 0, 0},
+		{"to an entry", act(ActionMoveDown, 4), 3, 0, 0},
+		{"to the assisted tab", act(ActionMoveRight, 1), 0, 0, 1},
+		{"down past the last assisted", act(ActionMoveDown, 5), 1, 0, 1},
+		{"filter out the assisted", filter("pek"), rowDate, 0, 1},
+		{"up to the name filter", act(ActionMoveUp, 1), rowName, 0, 1},
+		{"tab from the name filter", act(ActionMoveLeft, 1), rowName, 0, 0},
+		{"clear the name filter", filter(""), rowName, 0, 0},
+		{"down the honest tab", act(ActionMoveDown, 5), 3, 0, 0},
+		{"confirm the date filter", func() { act(ActionMoveUp, 4)(); act(ActionConfirm, 1)() }, rowDate, 1, 0},
+	}
+	for _, s := range steps {
+		s.do()
+		if h.cursor != s.cursor || h.dates != s.dates || h.tab != s.tab {
+			t.Fatalf("%v: cursor %v, dates %v, tab %v, want %v, %v, %v", s.name, h.cursor, h.dates, h.tab, s.cursor, s.dates, s.tab)
+		}
+	}
+
+	h.cursor = rowName
+	act(ActionConfirm, 1)()
+	if !h.editing {
+		t.Error("confirming the name filter does not edit it")
+	}
+}
+
+func TestHighscoresPaging(t *testing.T) {
+	tests := []struct {
+		entries int
+		cursor  int
+		page    int
+		pages   int
+	}{
+		{0, rowDate, 0, 0},
+		{MaxRanks, rowName, 0, 1},
+		{MaxRanks, MaxRanks - 1, 0, 1},
+		{MaxRanks + 1, MaxRanks, 1, 2},
+		{25, rowName, 0, 3},
+		{25, rowDate, 0, 3},
+		{25, 0, 0, 3},
+		{25, 9, 0, 3},
+		{25, 10, 1, 3},
+		{25, 19, 1, 3},
+		{25, 20, 2, 3},
+		{25, 24, 2, 3},
+		{MaxScores, MaxScores - 1, MaxScores/MaxRanks - 1, MaxScores / MaxRanks},
+	}
+	for _, tt := range tests {
+		h := scoreScreen(t, fullTable()[:tt.entries])
+		h.cursor = tt.cursor
+		page, pages := h.page()
+		if page != tt.page || pages != tt.pages {
+			t.Errorf("%v entries, cursor %v: page %v/%v, want %v/%v", tt.entries, tt.cursor, page, pages, tt.page, tt.pages)
+		}
+	}
+
+	// moving down a page's worth of entries turns the page
+	h := scoreScreen(t, fullTable()[:25])
+	for i := 0; i < MaxRanks; i++ {
+		h.action(ActionMoveDown)
+	}
+	if page, _ := h.page(); page != 1 {
+		t.Errorf("%v moves down from the top show page %v", MaxRanks, page+1)
+	}
+}
+
+func TestHighscoresDetails(t *testing.T) {
+	h := scoreScreen(t, datedRanks())
+	h.filter.Reset("pek")
+	h.refilter()
+	h.cursor = 1
+
+	if h.action(ActionConfirm) {
+		t.Fatal("confirming an entry left the screen")
+	}
+	if h.details == nil || h.details.Name != "Old PEKUJA" {
+		t.Fatalf("details of %+v, want Old PEKUJA", h.details)
+	}
+	if h.details != &h.tabs[0].scores.Ranks[3] {
+		t.Error("details show a copy of the entry")
+	}
+
+	// the details take the moves and watching without a replay does nothing
+	for _, a := range []Action{ActionMoveUp, ActionMoveDown, ActionMoveLeft, ActionMoveRight, ActionConfirm} {
+		if h.action(a) {
+			t.Fatalf("%v left the screen from the details", a)
+		}
+	}
+	if h.details == nil || h.cursor != 1 || h.tab != 0 || h.status != "" {
+		t.Fatalf("moves in the details: details %v, cursor %v, tab %v, status %q", h.details != nil, h.cursor, h.tab, h.status)
+	}
+
+	if h.action(ActionBack) || h.details != nil {
+		t.Fatal("back does not close the details")
+	}
+	if !h.action(ActionBack) {
+		t.Error("back from the table stays on the screen")
+	}
+}
+
+func TestRankDetails(t *testing.T) {
+	date := time.Date(2026, 3, 15, 8, 5, 0, 0, time.Local)
+	tests := []struct {
+		rank Rank
+		want [][2]string
+	}{
+		{
+			Rank{Name: "Pekuja", Value: 750, Date: &date, Frames: 95 * Fps, Phase: 4, Replay: "replay.json"},
+			[][2]string{
+				{"Name", "Pekuja"},
+				{"Score", "750"},
+				{"Date", "2026-03-15 08:05"},
+				{"Phase reached", "4"},
+				{"Duration", "1:35"},
+				{"Replay", "confirm to watch"},
+			},
+		},
+		{
+			Rank{Name: "Hectigo", Value: 1500},
+			[][2]string{
+				{"Name", "Hectigo"},
+				{"Score", "1500"},
+				{"Date", "unknown"},
+				{"Phase reached", "unknown"},
+				{"Duration", "unknown"},
+				{"Replay", "none"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got := rankDetails(&tt.rank)
+		if len(got) != len(tt.want) {
+			t.Errorf("%v: %q, want %q", tt.rank.Name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%v: %q, want %q", tt.rank.Name, got[i], tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+// copyEx maps a point of a w by h destination rectangle to where
+// SDL_RenderCopyEx draws it at the angle: turned clockwise on screen
+// around the center of the rectangle.
+func copyEx(x, y float64, w, h int, angle float64) (float64, float64) {
+	sin, cos := math.Sincos(angle * Radian)
+	cx, cy := float64(w)/2, float64(h)/2
+	dx, dy := x-cx, y-cy
+	return cx + dx*cos - dy*sin, cy + dx*sin + dy*cos
+}
+
+func TestRotatedBounds(t *testing.T) {
+	tests := []struct {
+		w, h  int
+		angle float64
+		want  image.Rectangle
+	}{
+		{6, 4, 0, image.Rect(0, 0, 6, 4)},
+		{6, 4, 90, image.Rect(1, -1, 5, 5)},
+		{6, 4, 180, image.Rect(0, 0, 6, 4)},
+		{6, 4, 270, image.Rect(1, -1, 5, 5)},
+		{6, 4, -90, image.Rect(1, -1, 5, 5)},
+		{6, 4, 30, image.Rect(-1, -2, 7, 6)},
+		{5, 3, 90, image.Rect(1, -1, 4, 4)},
+		{5, 3, 30, image.Rect(-1, -2, 6, 5)},
+		{34, 21, 45, image.Rect(-3, -9, 37, 30)},
+	}
+	for _, tt := range tests {
+		got := rotatedBounds(tt.w, tt.h, tt.angle)
+		if got != tt.want {
+			t.Errorf("%vx%v at %v: got %v, want %v", tt.w, tt.h, tt.angle, got, tt.want)
+		}
+	}
+}
+
+// TestRotateAlphaOrientation rotates an image with a different value in
+// every pixel and checks each lands where Blit draws it, which hands
+// CopyEx the negated angle: at right angles exactly, at others on the
+// nearest pixel.
+func TestRotateAlphaOrientation(t *testing.T) {
+	const w, h = 12, 8
+	src := image.NewAlpha(image.Rect(0, 0, w, h))
+	for i := range src.Pix {
+		src.Pix[i] = uint8(i + 1)
+	}
+
+	for _, angle := range []float64{0, 90, 180, 270, 30} {
+		dst := rotateAlpha(src, angle)
+		exact := math.Mod(angle, 90) == 0
+
+		n := 0
+		b := dst.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				v := dst.AlphaAt(x, y).A
+				if v == 0 {
+					continue
+				}
+				n++
+
+				sx, sy := int(v-1)%w, int(v-1)/w
+				px, py := copyEx(float64(sx)+0.5, float64(sy)+0.5, w, h, -angle)
+				dx, dy := px-(float64(x)+0.5), py-(float64(y)+0.5)
+				switch {
+				case exact && math.Hypot(dx, dy) > 1e-9:
+					t.Errorf("at %v: pixel %v,%v drawn at %v,%v, want %.0f,%.0f", angle, sx, sy, x, y, px-0.5, py-0.5)
+				case math.Hypot(dx, dy) > math.Sqrt2/2+1e-9:
+					t.Errorf("at %v: pixel %v,%v drawn at %v,%v, %.2f pixels from %.2f,%.2f", angle, sx, sy, x, y, math.Hypot(dx, dy), px-0.5, py-0.5)
+				}
+			}
+		}
+
+		switch {
+		case exact && n != w*h:
+			t.Errorf("at %v: %v pixels set, want %v", angle, n, w*h)
+		case n < w*h-(w+h) || n > w*h+(w+h):
+			t.Errorf("at %v: %v pixels set, want about %v", angle, n, w*h)
+		}
+	}
+}
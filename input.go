@@ -0,0 +1,385 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/qeedquan/go-media/sdl"
+)
+
+// Action is something the player wants to do, independent of the key,
+// button or stick that was used to ask for it.
+type Action int
+
+const (
+	ActionMoveLeft Action = iota
+	ActionMoveRight
+	ActionMoveUp
+	ActionMoveDown
+	ActionJump
+	ActionFire
+	ActionPause
+	ActionScreenshot
+	ActionBack
+	ActionConfirm
+	NumActions
+)
+
+var actionNames = [NumActions]string{
+	"move_left",
+	"move_right",
+	"move_up",
+	"move_down",
+	"jump",
+	"fire",
+	"pause",
+	"screenshot",
+	"back",
+	"confirm",
+}
+
+var actionTitles = [NumActions]string{
+	"Move left",
+	"Move right",
+	"Menu up",
+	"Menu down",
+	"Jump",
+	"Fire",
+	"Pause",
+	"Screenshot",
+	"Back",
+	"Confirm",
+}
+
+func (a Action) String() string {
+	return actionNames[a]
+}
+
+func (a Action) Title() string {
+	return actionTitles[a]
+}
+
+func ParseAction(name string) (Action, error) {
+	for i, n := range actionNames {
+		if n == name {
+			return Action(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown action %q", name)
+}
+
+type BindingKind int
+
+const (
+	BindKey BindingKind = iota
+	BindButton
+	BindAxis
+	BindMouse
+)
+
+// Binding is one physical input. Axes are split into their two
+// directions, Dir being -1 or 1.
+type Binding struct {
+	Kind BindingKind
+	Code int
+	Dir  int
+}
+
+var bindingKinds = []string{"key", "button", "axis", "mouse"}
+
+var buttonNames = []string{
+	"a", "b", "x", "y", "back", "guide", "start", "leftstick", "rightstick",
+	"leftshoulder", "rightshoulder", "dpup", "dpdown", "dpleft", "dpright",
+}
+
+var buttonTitles = []string{
+	"A", "B", "X", "Y", "Back", "Guide", "Start", "L3", "R3",
+	"LB", "RB", "D-Up", "D-Down", "D-Left", "D-Right",
+}
+
+var axisNames = []string{
+	"leftx", "lefty", "rightx", "righty", "lefttrigger", "righttrigger",
+}
+
+var axisTitles = []string{
+	"LS X", "LS Y", "RS X", "RS Y", "LT", "RT",
+}
+
+func (b Binding) String() string {
+	kind := bindingKinds[b.Kind]
+	switch b.Kind {
+	case BindKey:
+		// key names depend on the keyboard layout SDL has loaded, which
+		// it has not yet when the config is read, so store key codes
+		return kind + ":" + strconv.Itoa(b.Code)
+	case BindButton:
+		return kind + ":" + lookupName(buttonNames, b.Code)
+	case BindAxis:
+		sign := "+"
+		if b.Dir < 0 {
+			sign = "-"
+		}
+		return kind + ":" + lookupName(axisNames, b.Code) + sign
+	default:
+		return kind + ":" + strconv.Itoa(b.Code)
+	}
+}
+
+// Title is the short name shown in the controls menu.
+func (b Binding) Title() string {
+	switch b.Kind {
+	case BindKey:
+		return sdl.GetKeyName(sdl.Keycode(b.Code))
+	case BindButton:
+		return lookupName(buttonTitles, b.Code)
+	case BindAxis:
+		sign := "+"
+		if b.Dir < 0 {
+			sign = "-"
+		}
+		return lookupName(axisTitles, b.Code) + sign
+	default:
+		return fmt.Sprint("Mouse ", b.Code)
+	}
+}
+
+// device groups bindings by what produced them, so binding a new key
+// replaces the old key but keeps the controller and mouse bindings.
+func (b Binding) device() BindingKind {
+	if b.Kind == BindAxis {
+		return BindButton
+	}
+	return b.Kind
+}
+
+func ParseBinding(s string) (Binding, error) {
+	var b Binding
+
+	i := strings.Index(s, ":")
+	if i < 0 {
+		return b, fmt.Errorf("invalid binding %q", s)
+	}
+	kind, name := s[:i], s[i+1:]
+
+	switch kind {
+	case "key":
+		var err error
+		b.Kind = BindKey
+		b.Code, err = strconv.Atoi(name)
+		if err != nil {
+			b.Code = int(sdl.GetKeyFromName(name))
+		}
+		if b.Code == 0 {
+			return b, fmt.Errorf("unknown key %q", name)
+		}
+	case "button":
+		b.Kind = BindButton
+		b.Code = findName(buttonNames, name)
+		if b.Code < 0 {
+			return b, fmt.Errorf("unknown controller button %q", name)
+		}
+	case "axis":
+		b.Kind = BindAxis
+		if len(name) < 2 {
+			return b, fmt.Errorf("invalid controller axis %q", name)
+		}
+		switch name[len(name)-1] {
+		case '-':
+			b.Dir = -1
+		case '+':
+			b.Dir = 1
+		default:
+			return b, fmt.Errorf("controller axis %q needs a direction", name)
+		}
+		b.Code = findName(axisNames, name[:len(name)-1])
+		if b.Code < 0 {
+			return b, fmt.Errorf("unknown controller axis %q", name)
+		}
+	case "mouse":
+		var err error
+		b.Kind = BindMouse
+		b.Code, err = strconv.Atoi(name)
+		if err != nil {
+			return b, fmt.Errorf("invalid mouse button %q", name)
+		}
+	default:
+		return b, fmt.Errorf("unknown binding type %q", kind)
+	}
+
+	return b, nil
+}
+
+// BindingFor returns the binding for a key, button, stick or mouse press,
+// used when the player is choosing a new binding.
+func BindingFor(ev sdl.Event) (Binding, bool) {
+	switch ev := ev.(type) {
+	case sdl.KeyDownEvent:
+		return Binding{Kind: BindKey, Code: int(ev.Sym)}, true
+	case sdl.ControllerButtonDownEvent:
+		return Binding{Kind: BindButton, Code: int(ev.Button)}, true
+	case sdl.ControllerAxisEvent:
+		switch {
+		case ev.Value < -StickDeadzone:
+			return Binding{Kind: BindAxis, Code: int(ev.Axis), Dir: -1}, true
+		case ev.Value > StickDeadzone:
+			return Binding{Kind: BindAxis, Code: int(ev.Axis), Dir: 1}, true
+		}
+	case sdl.MouseButtonDownEvent:
+		return Binding{Kind: BindMouse, Code: int(ev.Button)}, true
+	}
+	return Binding{}, false
+}
+
+type ActionEvent struct {
+	Action Action
+	Down   bool
+}
+
+type Bindings [NumActions][]Binding
+
+func DefaultBindings() Bindings {
+	key := func(k sdl.Keycode) Binding { return Binding{Kind: BindKey, Code: int(k)} }
+	button := func(b sdl.GameControllerButton) Binding { return Binding{Kind: BindButton, Code: int(b)} }
+	axis := func(a sdl.GameControllerAxis, dir int) Binding {
+		return Binding{Kind: BindAxis, Code: int(a), Dir: dir}
+	}
+	mouse := func(b int) Binding { return Binding{Kind: BindMouse, Code: b} }
+
+	return Bindings{
+		ActionMoveLeft: {
+			key(sdl.K_LEFT),
+			button(sdl.CONTROLLER_BUTTON_DPAD_LEFT),
+			axis(sdl.CONTROLLER_AXIS_LEFTX, -1),
+		},
+		ActionMoveRight: {
+			key(sdl.K_RIGHT),
+			button(sdl.CONTROLLER_BUTTON_DPAD_RIGHT),
+			axis(sdl.CONTROLLER_AXIS_LEFTX, 1),
+		},
+		ActionMoveUp: {
+			key(sdl.K_UP),
+			button(sdl.CONTROLLER_BUTTON_DPAD_UP),
+			axis(sdl.CONTROLLER_AXIS_LEFTY, -1),
+		},
+		ActionMoveDown: {
+			key(sdl.K_DOWN),
+			button(sdl.CONTROLLER_BUTTON_DPAD_DOWN),
+			axis(sdl.CONTROLLER_AXIS_LEFTY, 1),
+		},
+		ActionJump: {
+			key(sdl.K_UP),
+			button(sdl.CONTROLLER_BUTTON_A),
+			button(sdl.CONTROLLER_BUTTON_DPAD_UP),
+			mouse(3),
+		},
+		ActionFire: {
+			key(sdl.K_SPACE),
+			button(sdl.CONTROLLER_BUTTON_B),
+			button(sdl.CONTROLLER_BUTTON_X),
+			mouse(1),
+		},
+		ActionPause: {
+			key(sdl.K_p),
+			key(sdl.K_RETURN),
+			button(sdl.CONTROLLER_BUTTON_START),
+		},
+		ActionScreenshot: {
+			key(sdl.K_s),
+			button(sdl.CONTROLLER_BUTTON_RIGHTSHOULDER),
+		},
+		ActionBack: {
+			key(sdl.K_ESCAPE),
+			button(sdl.CONTROLLER_BUTTON_BACK),
+		},
+		ActionConfirm: {
+			key(sdl.K_RETURN),
+			key(sdl.K_KP_ENTER),
+			key(sdl.K_SPACE),
+			button(sdl.CONTROLLER_BUTTON_A),
+			button(sdl.CONTROLLER_BUTTON_START),
+			mouse(1),
+		},
+	}
+}
+
+// Bind makes nb a binding for a, replacing whatever a was bound to on the
+// same kind of device.
+func (b *Bindings) Bind(a Action, nb Binding) {
+	list := b[a][:0]
+	for _, ob := range b[a] {
+		if ob.device() != nb.device() {
+			list = append(list, ob)
+		}
+	}
+	b[a] = append(list, nb)
+}
+
+// Translate turns a raw event into the actions bound to it. A key can be
+// bound to several actions; each screen only looks at the ones it uses.
+func (b *Bindings) Translate(ev sdl.Event) []ActionEvent {
+	var events []ActionEvent
+
+	match := func(kind BindingKind, code int, down bool) {
+		for a := range b {
+			for _, bi := range b[a] {
+				if bi.Kind == kind && bi.Code == code {
+					events = append(events, ActionEvent{Action(a), down})
+					break
+				}
+			}
+		}
+	}
+
+	switch ev := ev.(type) {
+	case sdl.KeyDownEvent:
+		match(BindKey, int(ev.Sym), true)
+	case sdl.KeyUpEvent:
+		match(BindKey, int(ev.Sym), false)
+	case sdl.ControllerButtonDownEvent:
+		match(BindButton, int(ev.Button), true)
+	case sdl.ControllerButtonUpEvent:
+		match(BindButton, int(ev.Button), false)
+	case sdl.MouseButtonDownEvent:
+		match(BindMouse, int(ev.Button), true)
+	case sdl.MouseButtonUpEvent:
+		match(BindMouse, int(ev.Button), false)
+	case sdl.ControllerAxisEvent:
+		prev, dir := controllers.Stick(ev)
+		if prev == dir {
+			break
+		}
+		for a := range b {
+			for _, bi := range b[a] {
+				if bi.Kind != BindAxis || bi.Code != int(ev.Axis) {
+					continue
+				}
+				switch bi.Dir {
+				case prev:
+					events = append(events, ActionEvent{Action(a), false})
+				case dir:
+					events = append(events, ActionEvent{Action(a), true})
+				}
+			}
+		}
+	}
+
+	return events
+}
+
+func lookupName(names []string, i int) string {
+	if 0 <= i && i < len(names) {
+		return names[i]
+	}
+	return strconv.Itoa(i)
+}
+
+func findName(names []string, name string) int {
+	name = strings.ToLower(name)
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestLevelSpawn(t *testing.T) {
+	InitHeadless()
+
+	const frames = 100
+	tests := []struct {
+		name   string
+		offset int
+		delay  int
+		want   []int
+	}{
+		{"once at the start", 0, -1, []int{0}},
+		{"once later", 37, -1, []int{37}},
+		{"never", 0, 0, nil},
+		{"never with an offset", 10, 0, nil},
+		{"every frame", 0, 1, nil},
+		{"periodic", 5, 25, []int{5, 30, 55, 80}},
+		{"periodic from the start", 0, 40, []int{0, 40, 80}},
+	}
+	for _, tt := range tests {
+		m := &Map{
+			ID:     "test",
+			Length: []int{frames},
+			Sea:    []*SeaState{&DefaultSea},
+			Phase:  [][]Spawn{{{Offset: tt.offset, Delay: tt.delay, SpawnEvent: SpawnEvent{Kind: "pirate", Count: 2}}}},
+		}
+		var l Level
+		l.Reset(m, rand.New(rand.NewSource(1)))
+
+		var fired []int
+		for f := 0; f < frames; f++ {
+			for _, e := range l.Spawn() {
+				if e.Kind != "pirate" || e.Count != 2 {
+					t.Fatalf("%v: spawned %+v", tt.name, e)
+				}
+				fired = append(fired, f)
+			}
+		}
+
+		want := tt.want
+		if tt.delay == 1 {
+			for f := 0; f < frames; f++ {
+				want = append(want, f)
+			}
+		}
+		if len(fired) != len(want) {
+			t.Errorf("%v: fired on frames %v, want %v", tt.name, fired, want)
+			continue
+		}
+		for i := range want {
+			if fired[i] != want[i] {
+				t.Errorf("%v: fired on frames %v, want %v", tt.name, fired, want)
+				break
+			}
+		}
+	}
+}
+
+func TestParseSpawnTiming(t *testing.T) {
+	tests := []struct {
+		entry string
+		ok    bool
+	}{
+		{`{"kind": "shark", "offset": 0, "delay": -1}`, true},
+		{`{"kind": "shark", "offset": 150, "delay": -1}`, true},
+		{`{"kind": "shark", "offset": 0, "delay": 0}`, true},
+		{`{"kind": "shark", "offset": 299, "delay": 300}`, true},
+		{`{"kind": "shark", "offset": 300, "delay": 300}`, false},
+		{`{"kind": "shark", "offset": 500, "delay": 300}`, false},
+		{`{"kind": "shark", "offset": -1, "delay": 300}`, false},
+		{`{"kind": "shark", "offset": 0, "delay": -2}`, false},
+		{`{"kind": "shark", "offset": 0}`, false},
+	}
+	for _, tt := range tests {
+		_, err := parsePhases([]byte("[[" + tt.entry + "]]"))
+		switch {
+		case tt.ok && err != nil:
+			t.Errorf("%v: %v", tt.entry, err)
+		case !tt.ok && err == nil:
+			t.Errorf("%v: accepted", tt.entry)
+		}
+	}
+}
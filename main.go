@@ -1,12 +1,11 @@
 package main
 
 import (
+	"flag"
 	"log"
-	"math/rand"
 	"os"
 	"runtime"
 	"runtime/pprof"
-	"time"
 
 	"github.com/qeedquan/go-media/sdl"
 	"github.com/qeedquan/go-media/sdl/sdlimage/sdlcolor"
@@ -14,25 +13,13 @@ import (
 	"github.com/qeedquan/go-media/sdl/sdlttf"
 )
 
-type Display struct {
-	*sdl.Window
-	*sdl.Renderer
-}
-
-func newDisplay(w, h int, wflag sdl.WindowFlags) (*Display, error) {
-	window, renderer, err := sdl.CreateWindowAndRenderer(w, h, wflag)
-	if err != nil {
-		return nil, err
-	}
-	return &Display{window, renderer}, nil
-}
-
 const (
 	W            = 400
 	H            = 300
 	MaxHearts    = 5
 	MaxName      = 32
 	MaxRanks     = 10
+	MaxScores    = 100
 	MinFireDelay = 1
 	Fps          = 30
 )
@@ -40,27 +27,37 @@ const (
 var (
 	config Config
 
-	screen    *Display
+	display   *Display
+	screen    Renderer
 	smallFont *sdlttf.Font
 	bigFont   *sdlttf.Font
-	texture   *sdl.Texture
 	surface   *sdl.Surface
 
 	song *Music
 
-	frame   = time.NewTicker(1000 / Fps * time.Millisecond)
 	profile *os.File
 )
 
 func main() {
 	runtime.LockOSThread()
 	log.SetFlags(0)
-	rand.Seed(time.Now().UnixNano())
 	config.Parse()
+	if flag.Arg(0) == "scores" {
+		ScoresCommand(flag.Args()[1:])
+		return
+	}
 	Profile()
-	InitSDL()
 	defer Quit()
+	if config.Simulate > 0 {
+		Simulate(config.Simulate, config.SimulateMap())
+		return
+	}
+	InitSDL()
 	Load()
+	if config.Replay != "" {
+		PlayReplay(config.Replay)
+		return
+	}
 	Loop()
 }
 
@@ -103,25 +100,21 @@ func InitSDL() {
 
 	sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, "linear")
 
-	screen, err = newDisplay(W, H, wflag)
+	display, err = newDisplay(W, H, wflag)
 	if err != nil {
 		log.Fatal(err)
 	}
-	screen.SetLogicalSize(W, H)
+	display.SetLogicalSize(W, H)
+	screen = display
 
 	sdl.ShowCursor(0)
 
-	texture, err = screen.CreateTexture(sdl.PIXELFORMAT_ABGR8888, sdl.TEXTUREACCESS_STREAMING, W, H)
-	if err != nil {
-		log.Fatal(err)
-	}
-
 	surface, err = sdl.CreateRGBSurfaceWithFormat(sdl.SWSURFACE, W, H, 32, sdl.PIXELFORMAT_ABGR8888)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	screen.SetTitle("Trip on the Funny Boat")
+	display.SetTitle("Trip on the Funny Boat")
 	screen.SetDrawColor(sdlcolor.Black)
 	screen.Clear()
 	screen.Present()
@@ -130,7 +123,7 @@ func InitSDL() {
 	if err != nil {
 		log.Print(err)
 	} else {
-		screen.SetIcon(icon)
+		display.SetIcon(icon)
 	}
 	icon.Free()
 
@@ -146,6 +139,7 @@ func Load() {
 
 	InitWater()
 	InitClouds()
+	InitControllers()
 }
 
 func Loop() {
@@ -155,11 +149,11 @@ func Loop() {
 	var game Game
 
 	mainMode := []string{"New Game", "High Scores", "Options", "Quit"}
-	playMode := []string{"Story Mode", "Endless Mode"}
+	playMode := []string{"Story Mode", "Endless Mode", "Custom Campaign"}
 
 	menu.Init()
 	options.Init()
-	highscores.Init()
+	highscores.Init(&game)
 	game.Init()
 
 	mainSelection := 0
@@ -171,21 +165,33 @@ loop:
 			selection := 0
 			selection = menu.Run(playMode, selection)
 
-			var endless bool
+			var m *Map
+			var err error
 			switch selection {
 			case 0:
-				endless = false
+				m, err = LoadMap("story")
 			case 1:
-				endless = true
-			default:
+				m, err = LoadMap("endless")
+			case 2:
+				m = chooseMap(&menu)
+			}
+			if err != nil {
+				log.SetPrefix("map: ")
+				log.Print(err)
+			}
+			if m == nil {
 				continue loop
 			}
 
-			score := -1
+			var entry *Rank
+			var replay *Replay
 			if mainSelection == 0 {
-				score = game.Run(endless)
+				game.Run(m, NewSeed())
+				result := game.Result()
+				entry = &result
+				replay = game.Replay()
 			}
-			highscores.Run(endless, score)
+			highscores.Run(m, entry, replay)
 		case 2: // Options
 			options.Run()
 		default: // Quit
@@ -194,6 +200,52 @@ loop:
 	}
 }
 
+func chooseMap(menu *Menu) *Map {
+	maps := ListMaps()
+	if len(maps) == 0 {
+		menu.Run([]string{"No custom campaigns"}, 0)
+		return nil
+	}
+
+	names := make([]string, len(maps))
+	for i, m := range maps {
+		names[i] = m.Name
+	}
+
+	i := menu.Run(names, 0)
+	if i < 0 {
+		return nil
+	}
+	return maps[i]
+}
+
+func PlayReplay(filename string) {
+	var game Game
+	game.Init()
+	err := WatchReplay(&game, filename)
+	if err != nil {
+		log.SetPrefix("replay: ")
+		log.Fatal(err)
+	}
+}
+
+// WatchReplay plays back a recorded session on the map it was recorded on
+// in the given game, which is reused so watching does not make textures.
+func WatchReplay(game *Game, filename string) error {
+	replay, err := LoadReplay(filename)
+	if err != nil {
+		return err
+	}
+
+	m, err := LoadMap(replay.Map)
+	if err != nil {
+		return err
+	}
+
+	game.Play(m, replay)
+	return nil
+}
+
 func Quit() {
 	sdl.Quit()
 	if profile != nil {
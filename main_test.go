@@ -0,0 +1,52 @@
+package main
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/qeedquan/go-media/sdl"
+)
+
+// TestMain points the game at the resources in the tree and keeps the
+// config directory of the user out of reach of the tests.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "funnyboat")
+	if err != nil {
+		log.Fatal(err)
+	}
+	config.Resource = "data"
+	config.Dir = dir
+	config.Name = "Funny Boat"
+	config.Bindings = DefaultBindings()
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// benchmarkDisplay draws the rest of the benchmark into a hidden window,
+// so it measures what the renderer does with the calls. Benchmarks are
+// skipped where no window can be opened; without a display, setting
+// SDL_VIDEODRIVER=dummy runs them on the software renderer.
+func benchmarkDisplay(b *testing.B) {
+	b.Helper()
+	err := sdl.Init(sdl.INIT_VIDEO)
+	if err != nil {
+		b.Skip(err)
+	}
+	d, err := newDisplay(W, H, sdl.WINDOW_HIDDEN)
+	if err != nil {
+		sdl.Quit()
+		b.Skip(err)
+	}
+
+	prev := screen
+	screen = d
+	b.Cleanup(func() {
+		screen = prev
+		d.Renderer.Destroy()
+		d.Window.Destroy()
+		sdl.Quit()
+	})
+}
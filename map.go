@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Map is a campaign: how long each phase lasts, what is announced and
+// what spawns during it. Length, Sea and Phase are cycled through
+// independently, so an endless map can mix short lists. Message holds the
+// banners of the first phases and Announce is queued after them at the
+// start of every phase.
+type Map struct {
+	ID       string
+	Name     string
+	Endless  bool
+	Length   []int
+	Message  [][]*Banner
+	Announce []*Banner
+	Color    []string
+	Sea      []*SeaState
+	Phase    [][]Spawn
+}
+
+// Version 1 phases were objects mapping each kind to [offset, delay],
+// version 2 phases are lists of spawn entries.
+const mapVersion = 2
+
+// spawnKinds lists what version 1 maps could spawn, in the order their
+// phases fired. Newer maps can spawn any registered actor.
+var spawnKinds = []string{"shark", "pirate", "mine", "seagull", "titanic", "powerup"}
+
+type mapFile struct {
+	Version  int               `json:"version"`
+	Name     string            `json:"name"`
+	Endless  bool              `json:"endless"`
+	Length   []int             `json:"length"`
+	Message  []json.RawMessage `json:"message"`
+	Announce json.RawMessage   `json:"announce"`
+	Color    []string          `json:"color"`
+	Weather  []float64         `json:"weather"`
+	Sea      []json.RawMessage `json:"sea"`
+	Phase    json.RawMessage   `json:"phase"`
+}
+
+type spawnEntry struct {
+	Kind    string      `json:"kind"`
+	Offset  int         `json:"offset"`
+	Delay   *int        `json:"delay"`
+	Count   int         `json:"count"`
+	Pos     *[2]float64 `json:"pos"`
+	Vel     *[2]float64 `json:"vel"`
+	Spacing *[2]float64 `json:"spacing"`
+	Variant string      `json:"variant"`
+}
+
+// MapPath turns a map id into a file name. Bare names like "story" refer
+// to maps in the resource directory, anything else is a path.
+func MapPath(id string) string {
+	if filepath.Ext(id) == "" && !strings.ContainsRune(id, filepath.Separator) && !strings.ContainsRune(id, '/') {
+		return filepath.Join(config.Resource, "maps", id+".json")
+	}
+	return id
+}
+
+func LoadMap(id string) (*Map, error) {
+	filename := MapPath(id)
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := parseMap(buf)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+	m.ID = id
+	if m.Name == "" {
+		m.Name = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	}
+	return m, nil
+}
+
+func parseMap(buf []byte) (*Map, error) {
+	var f mapFile
+
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&f)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case f.Version == 0:
+		return nil, fmt.Errorf("missing version")
+	case f.Version > mapVersion:
+		return nil, fmt.Errorf("version %v is newer than the supported version %v", f.Version, mapVersion)
+	case len(f.Length) == 0:
+		return nil, fmt.Errorf("length: need at least one phase length")
+	case len(f.Weather) == 0 && len(f.Sea) == 0:
+		return nil, fmt.Errorf("weather: need at least one value or a sea state")
+	case len(f.Weather) != 0 && len(f.Sea) != 0:
+		return nil, fmt.Errorf("weather: give either wave amplitudes or sea states, not both")
+	}
+
+	for i, n := range f.Length {
+		if n < -1 || n == 0 {
+			return nil, fmt.Errorf("length %v: %v is not a positive number of frames or -1 for forever", i, n)
+		}
+	}
+
+	m := &Map{
+		Name:    f.Name,
+		Endless: f.Endless,
+		Length:  f.Length,
+		Color:   f.Color,
+	}
+
+	for i, w := range f.Weather {
+		if w < 0 {
+			return nil, fmt.Errorf("weather %v: wave amplitude %v is negative", i, w)
+		}
+		m.Sea = append(m.Sea, WeatherSea(w))
+	}
+
+	for i, buf := range f.Sea {
+		s, err := parseSea(buf)
+		if err != nil {
+			return nil, fmt.Errorf("sea %v: %v", i, err)
+		}
+		m.Sea = append(m.Sea, s)
+	}
+
+	for i, msg := range f.Message {
+		banners, err := parseBanners(msg)
+		if err != nil {
+			return nil, fmt.Errorf("message %v: %v", i, err)
+		}
+		m.Message = append(m.Message, banners)
+	}
+
+	if len(f.Announce) > 0 {
+		m.Announce, err = parseBanners(f.Announce)
+		if err != nil {
+			return nil, fmt.Errorf("announce: %v", err)
+		}
+	}
+
+	if f.Version == 1 {
+		m.Phase, err = parsePhasesV1(f.Phase)
+	} else {
+		m.Phase, err = parsePhases(f.Phase)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Phase) == 0 {
+		return nil, fmt.Errorf("phase: need at least one phase")
+	}
+
+	return m, nil
+}
+
+func parsePhases(buf []byte) ([][]Spawn, error) {
+	var phases [][]spawnEntry
+
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&phases)
+	if err != nil {
+		return nil, fmt.Errorf("phase: %v", err)
+	}
+
+	var p [][]Spawn
+	for i, entries := range phases {
+		var phase []Spawn
+		for j, e := range entries {
+			s, err := e.spawn()
+			if err != nil {
+				return nil, fmt.Errorf("phase %v: entry %v: %v", i, j, err)
+			}
+			phase = append(phase, s)
+		}
+		p = append(p, phase)
+	}
+	return p, nil
+}
+
+func parsePhasesV1(buf []byte) ([][]Spawn, error) {
+	var phases []map[string][]int
+
+	err := json.Unmarshal(buf, &phases)
+	if err != nil {
+		return nil, fmt.Errorf("phase: %v", err)
+	}
+
+	var p [][]Spawn
+	for i, kinds := range phases {
+		for kind, s := range kinds {
+			if findName(spawnKinds, kind) < 0 {
+				return nil, fmt.Errorf("phase %v: unknown spawn %q, expected one of %v", i, kind, strings.Join(spawnKinds, ", "))
+			}
+			if len(s) != 2 {
+				return nil, fmt.Errorf("phase %v: %v: expected [offset, delay]", i, kind)
+			}
+		}
+
+		// fire in the fixed kind order version 1 used
+		var phase []Spawn
+		for _, kind := range spawnKinds {
+			s, ok := kinds[kind]
+			if !ok {
+				continue
+			}
+			e := spawnEntry{Kind: kind, Offset: s[0], Delay: &s[1]}
+			sp, err := e.spawn()
+			if err != nil {
+				return nil, fmt.Errorf("phase %v: %v", i, err)
+			}
+			phase = append(phase, sp)
+		}
+		p = append(p, phase)
+	}
+	return p, nil
+}
+
+func (e *spawnEntry) spawn() (Spawn, error) {
+	name := strings.ToLower(e.Kind)
+	kind := actorKinds[name]
+	if kind == nil {
+		return Spawn{}, fmt.Errorf("unknown spawn %q, expected one of %v", e.Kind, strings.Join(ActorNames(), ", "))
+	}
+	variants := kind.Variants
+	variant := findName(variants, e.Variant)
+
+	switch {
+	case e.Delay == nil:
+		return Spawn{}, fmt.Errorf("%v: missing delay", name)
+	case e.Offset < 0:
+		return Spawn{}, fmt.Errorf("%v: offset %v is negative", name, e.Offset)
+	case *e.Delay < -1:
+		return Spawn{}, fmt.Errorf("%v: delay %v is not a number of frames, 0 for never or -1 for once", name, *e.Delay)
+	case *e.Delay > 0 && e.Offset >= *e.Delay:
+		return Spawn{}, fmt.Errorf("%v: offset %v is not less than delay %v, so it never fires", name, e.Offset, *e.Delay)
+	case e.Count < 0:
+		return Spawn{}, fmt.Errorf("%v: count %v is negative", name, e.Count)
+	case kind.Unique && e.Count > 1:
+		return Spawn{}, fmt.Errorf("%v: only one can be afloat at a time", name)
+	case e.Variant != "" && len(variants) == 0:
+		return Spawn{}, fmt.Errorf("%v: has no variants", name)
+	case e.Variant != "" && variant < 0:
+		return Spawn{}, fmt.Errorf("%v: unknown variant %q, expected one of %v", name, e.Variant, strings.Join(variants, ", "))
+	}
+
+	s := Spawn{
+		Offset: e.Offset,
+		Delay:  *e.Delay,
+		SpawnEvent: SpawnEvent{
+			Kind:  name,
+			Count: e.Count,
+		},
+	}
+	if variant >= 0 {
+		s.Variant = variants[variant]
+	}
+	if e.Pos != nil {
+		s.Pos = &Point{e.Pos[0], e.Pos[1]}
+	}
+	if e.Vel != nil {
+		s.Vel = &Point{e.Vel[0], e.Vel[1]}
+	}
+	if e.Spacing != nil {
+		s.Spacing = Point{e.Spacing[0], e.Spacing[1]}
+	}
+	return s, nil
+}
+
+// ListMaps returns the custom campaigns found in the resource and config
+// map directories, plus the one given on the command line.
+func ListMaps() []*Map {
+	var ids []string
+	if config.Map != "" {
+		ids = append(ids, config.Map)
+	}
+
+	dirs := []string{filepath.Join(config.Resource, "maps")}
+	if path, err := config.Path(); err == nil {
+		dirs = append(dirs, filepath.Join(path, "maps"))
+	}
+
+	for i, dir := range dirs {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+		sort.Strings(matches)
+		for _, name := range matches {
+			id := name
+			if i == 0 {
+				id = strings.TrimSuffix(filepath.Base(name), ".json")
+				if id == "story" || id == "endless" {
+					continue
+				}
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	log.SetPrefix("map: ")
+	var maps []*Map
+	for _, id := range ids {
+		m, err := LoadMap(id)
+		if err != nil {
+			log.Print(err)
+			continue
+		}
+		maps = append(maps, m)
+	}
+	return maps
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"image"
+	"math"
+)
+
+// MaxMaskShift is how far in pixels rounding an angle to the nearest
+// cached mask may move the corners of a sprite. Keeping it under a pixel
+// keeps the cached masks within the outline of exactly rotated ones.
+const MaxMaskShift = 0.5
+
+// Masks caches the rotated collision masks of an image, computed the first
+// time an angle is asked for. Copies of an image share its masks. Larger
+// images get finer steps, always a whole number per degree so masks at
+// whole degrees are exact.
+type Masks struct {
+	store   *image.Alpha
+	rotated []*image.Alpha
+}
+
+func newMasks(store *image.Alpha) *Masks {
+	// rounding is off by at most half a step, which moves a corner at
+	// radius r by r times that many radians
+	b := store.Bounds()
+	r := math.Hypot(float64(b.Dx()), float64(b.Dy())) / 2
+	perDegree := Max(1, int(math.Ceil(r*math.Pi/MaxMaskShift/360)))
+	return &Masks{
+		store:   store,
+		rotated: make([]*image.Alpha, 360*perDegree),
+	}
+}
+
+// At returns the mask for the angle rounded to the nearest step. Its bounds
+// are relative to the image's top left corner, so a rotated mask reaches
+// past the image on the sides the way the rotated sprite does.
+func (m *Masks) At(angle float64) *image.Alpha {
+	n := len(m.rotated)
+	i := int(math.Round(angle*float64(n)/360)) % n
+	if i < 0 {
+		i += n
+	}
+	if i == 0 {
+		return m.store
+	}
+
+	if m.rotated[i] == nil {
+		m.rotated[i] = rotateAlpha(m.store, float64(i*360)/float64(n))
+	}
+	return m.rotated[i]
+}
+
+// Invalidate drops the rotated masks after the store was drawn on.
+func (m *Masks) Invalidate() {
+	for i := range m.rotated {
+		m.rotated[i] = nil
+	}
+}
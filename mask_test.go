@@ -0,0 +1,141 @@
+package main
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+// maskSprites are the sprites that collide while tilted or spinning.
+var maskSprites = []string{"laiva", "hai", "merkkari", "titanic", "lokki1", "lokki2", "lokki3", "kuti", "erikoiskuti", "miina"}
+
+// maskAngles are tilts of boats, sharks and gulls on rough water and the
+// headings of cannonballs, mostly between the mask steps.
+var maskAngles = []float64{
+	-44.6, -30.2, -12.7, -3.4, -0.6, -0.2, 0.3, 0.49, 1, 2.5, 7.77, 15.5, 33.3, 45,
+	89.6, 90, 135.4, 180, 181.2, 270.5, 359.7, 412.25,
+}
+
+func maskSolid(m *image.Alpha, x, y int) bool {
+	return m.AlphaAt(x, y).A != 0
+}
+
+// maskSlack is how many pixels a cached mask may be off the outline of the
+// exact one.
+const maskSlack = 1
+
+// nearSolid reports whether a pixel within maskSlack pixels of (x, y) is
+// solid or empty as asked.
+func nearSolid(m *image.Alpha, x, y int, solid bool) bool {
+	for dy := -maskSlack; dy <= maskSlack; dy++ {
+		for dx := -maskSlack; dx <= maskSlack; dx++ {
+			if maskSolid(m, x+dx, y+dy) == solid {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TestMasksMatchExactRotation compares the cached masks with rotating the
+// sprite at the exact angle, which is what collision did before the
+// cache. At whole degrees they are the same. Between steps the cached mask
+// is at most half a step off, which may only move the outline: every
+// pixel the two disagree on is next to a pixel of the exact mask that
+// agrees with the cached one.
+func TestMasksMatchExactRotation(t *testing.T) {
+	InitHeadless()
+
+	for _, name := range maskSprites {
+		img := LoadImage(name)
+		masks := newMasks(img.Store)
+		for _, angle := range maskAngles {
+			cached := masks.At(angle)
+			exact := rotateAlpha(img.Store, angle)
+			onStep := angle == math.Trunc(angle)
+
+			b := cached.Bounds().Union(exact.Bounds())
+			diff, bad := 0, 0
+			for y := b.Min.Y; y < b.Max.Y; y++ {
+				for x := b.Min.X; x < b.Max.X; x++ {
+					c := maskSolid(cached, x, y)
+					if c == maskSolid(exact, x, y) {
+						continue
+					}
+					diff++
+					if !nearSolid(exact, x, y, c) {
+						bad++
+					}
+				}
+			}
+
+			switch {
+			case onStep && diff != 0:
+				t.Errorf("%v at %v: %v pixels differ on a mask step", name, angle, diff)
+			case bad != 0:
+				t.Errorf("%v at %v: %v of %v differing pixels are off the outline", name, angle, bad, diff)
+			}
+		}
+	}
+}
+
+// TestCollisionMatchesExactRotation checks collision between tilted
+// entities against the exact masks. Either cached mask may be off by its
+// outline, so the results may only differ where the exact masks grown by
+// a pixel overlap while the ones shrunk by a pixel do not.
+func TestCollisionMatchesExactRotation(t *testing.T) {
+	InitHeadless()
+
+	hit := func(m1, m2 *image.Alpha, o1, o2 image.Point, grow int) bool {
+		r := m1.Bounds().Add(o1).Inset(-grow).Intersect(m2.Bounds().Add(o2).Inset(-grow))
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				s1 := maskSolid(m1, x-o1.X, y-o1.Y)
+				s2 := maskSolid(m2, x-o2.X, y-o2.Y)
+				if grow > 0 {
+					s1 = nearSolid(m1, x-o1.X, y-o1.Y, true)
+					s2 = nearSolid(m2, x-o2.X, y-o2.Y, true)
+				} else if grow < 0 {
+					s1 = s1 && !nearSolid(m1, x-o1.X, y-o1.Y, false)
+					s2 = s2 && !nearSolid(m2, x-o2.X, y-o2.Y, false)
+				}
+				if s1 && s2 {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	boat := LoadImage("laiva")
+	for _, name := range []string{"hai", "merkkari", "kuti", "erikoiskuti", "miina"} {
+		img := LoadImage(name)
+		for i, a1 := range maskAngles {
+			a2 := maskAngles[(i*7+3)%len(maskAngles)]
+			var p1, p2 Entity
+			p1.Images = []*Image{boat.Copy()}
+			p2.Images = []*Image{img.Copy()}
+			p1.UpdateAngle(a1)
+			p2.UpdateAngle(a2)
+
+			e1, e2 := rotateAlpha(boat.Store, a1), rotateAlpha(img.Store, a2)
+			for dy := -img.H - 4; dy <= boat.H+4; dy += 3 {
+				for dx := -img.W - 4; dx <= boat.W+4; dx += 3 {
+					p1.Pos = Point{100, 100}
+					p2.Pos = Point{float64(100 + dx), float64(100 + dy)}
+					o1, o2 := collisionOrigin(&p1), collisionOrigin(&p2)
+
+					got := Collision(&p1, &p2)
+					exact := hit(e1, e2, o1, o2, 0)
+					switch {
+					case got == exact:
+					case got && !hit(e1, e2, o1, o2, 1):
+						t.Errorf("%v at %v, %v off by %v,%v: hit a pixel away from the exact masks", name, a1, a2, dx, dy)
+					case !got && hit(e1, e2, o1, o2, -1):
+						t.Errorf("%v at %v, %v off by %v,%v: missed a hit deeper than the outline", name, a1, a2, dx, dy)
+					}
+				}
+			}
+		}
+	}
+}
@@ -10,11 +10,9 @@ func (m *Menu) Init() {
 	m.Selector.Init(bigFont, m.event)
 }
 
-func (m *Menu) event(ev sdl.Event) {
-	switch ev := ev.(type) {
-	case sdl.KeyDownEvent:
-		switch ev.Sym {
-		case sdl.K_SPACE, sdl.K_RETURN, sdl.K_KP_ENTER:
+func (m *Menu) event(ev sdl.Event, actions []ActionEvent) {
+	for _, a := range actions {
+		if a.Down && a.Action == ActionConfirm {
 			m.Quit()
 		}
 	}
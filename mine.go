@@ -12,8 +12,18 @@ type Mine struct {
 	ExplodeFrames int
 }
 
-func (m *Mine) Init() {
-	h := H - int(WaterLevel(rand.Float64()*320)) - 4
+func init() {
+	RegisterActor("mine", ActorKind{New: newMine})
+}
+
+func newMine(g *Game, ev SpawnEvent) Actor {
+	m := &Mine{}
+	m.Init(g.rng)
+	return m
+}
+
+func (m *Mine) Init(rng *rand.Rand) {
+	h := H - int(WaterLevel(rng.Float64()*320)) - 4
 	p := LoadImage("miina")
 	i := p.CopySize(p.W, p.H+h)
 
@@ -32,7 +42,26 @@ func (m *Mine) Init() {
 	i.Unbind()
 }
 
-func (m *Mine) Update() {
+func (m *Mine) Body() *Entity { return &m.Entity }
+func (m *Mine) Layer() Layer  { return LayerEnemy }
+
+func (m *Mine) Gone() bool {
+	return (m.Exploding && m.ExplodeFrames == 0) || m.Pos.Right(m.Image()) < 0
+}
+
+func (m *Mine) Touch(g *Game) {
+	if !m.Exploding && Collision(&g.player.Entity, &m.Entity) {
+		g.damagePlayer()
+		m.Explode()
+		SplashWater(m.Pos.CenterX(m.Image()), 24, -5)
+	}
+}
+
+// Cannonballs pass mines by.
+func (m *Mine) Hit(g *Game, c *Cannon) bool { return false }
+func (m *Mine) Value(c *Cannon) int         { return 0 }
+
+func (m *Mine) Update(g *Game) {
 	m.Pos = m.Pos.Add(m.Vel)
 
 	i := m.Image()
@@ -47,6 +76,12 @@ func (m *Mine) Update() {
 		if m.ExplodeFrames > 0 {
 			m.ExplodeFrames--
 		}
+
+		x := m.Pos.CenterX(i)
+		y := m.Pos.Y + float64(i.W)/2
+		p := Point{x, y}
+		g.ensemble.Explosion(p)
+		g.ensemble.Debris(p)
 	}
 }
 
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/qeedquan/go-media/sdl"
+)
+
+// nameLetters are what the letter picker cycles through.
+const nameLetters = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789 .-_!?"
+
+// NameEntry edits a player name. On the keyboard the name is typed, with
+// the arrows moving the cursor. On a controller left and right move the
+// cursor, up and down pick the letter under it and fire erases the letter
+// before it. Confirm finishes and back gives up on the changes.
+type NameEntry struct {
+	name   []rune
+	orig   string
+	cursor int
+	Done   bool
+}
+
+func (n *NameEntry) Reset(name string) {
+	n.orig = name
+	n.name = []rune(name)
+	if len(n.name) > MaxName {
+		n.name = n.name[:MaxName]
+	}
+	n.cursor = len(n.name)
+	n.Done = false
+}
+
+// Name returns the name entered so far, falling back to the one it
+// started with when it is blank.
+func (n *NameEntry) Name() string {
+	name := n.Text()
+	if name == "" {
+		return n.orig
+	}
+	return name
+}
+
+// Text returns the name entered so far, which may be blank.
+func (n *NameEntry) Text() string {
+	return strings.TrimSpace(string(n.name))
+}
+
+// Split returns the name around the cursor.
+func (n *NameEntry) Split() (before, after string) {
+	return string(n.name[:n.cursor]), string(n.name[n.cursor:])
+}
+
+func (n *NameEntry) Event(ev sdl.Event) {
+	switch ev := ev.(type) {
+	case sdl.KeyDownEvent:
+		n.key(ev.Sym)
+		return
+	case sdl.TextInputEvent:
+		text := string(ev.Text[:])
+		if i := strings.IndexByte(text, 0); i >= 0 {
+			text = text[:i]
+		}
+		for _, r := range text {
+			n.insert(r)
+		}
+		return
+	case sdl.KeyUpEvent:
+		return
+	}
+
+	// the mouse button both fires and confirms, confirming wins
+	actions := config.Bindings.Translate(ev)
+	confirm := false
+	for _, a := range actions {
+		confirm = confirm || (a.Down && a.Action == ActionConfirm)
+	}
+
+	for _, a := range actions {
+		if !a.Down {
+			continue
+		}
+		switch a.Action {
+		case ActionMoveLeft:
+			n.move(-1)
+		case ActionMoveRight:
+			n.move(1)
+		case ActionMoveUp:
+			n.pick(1)
+		case ActionMoveDown:
+			n.pick(-1)
+		case ActionFire:
+			if !confirm {
+				n.erase()
+			}
+		case ActionConfirm:
+			n.Done = true
+		case ActionBack:
+			n.Reset(n.orig)
+			n.Done = true
+		}
+	}
+}
+
+func (n *NameEntry) key(sym sdl.Keycode) {
+	switch sym {
+	case sdl.K_RETURN, sdl.K_KP_ENTER:
+		n.Done = true
+	case sdl.K_ESCAPE:
+		n.Reset(n.orig)
+		n.Done = true
+	case sdl.K_BACKSPACE:
+		n.erase()
+	case sdl.K_DELETE:
+		if n.cursor < len(n.name) {
+			n.name = append(n.name[:n.cursor], n.name[n.cursor+1:]...)
+		}
+	case sdl.K_LEFT:
+		n.move(-1)
+	case sdl.K_RIGHT:
+		n.move(1)
+	case sdl.K_HOME:
+		n.cursor = 0
+	case sdl.K_END:
+		n.cursor = len(n.name)
+	case sdl.K_UP:
+		n.pick(1)
+	case sdl.K_DOWN:
+		n.pick(-1)
+	}
+}
+
+func (n *NameEntry) insert(r rune) {
+	if len(n.name) >= MaxName || r < ' ' {
+		return
+	}
+	n.name = append(n.name, 0)
+	copy(n.name[n.cursor+1:], n.name[n.cursor:])
+	n.name[n.cursor] = r
+	n.cursor++
+}
+
+func (n *NameEntry) erase() {
+	if n.cursor > 0 {
+		n.name = append(n.name[:n.cursor-1], n.name[n.cursor:]...)
+		n.cursor--
+	}
+}
+
+func (n *NameEntry) move(dir int) {
+	n.cursor = Max(0, Min(n.cursor+dir, len(n.name)))
+}
+
+// pick turns the letter under the cursor to the next or previous one of
+// nameLetters. Past the end of the name it adds a letter.
+func (n *NameEntry) pick(dir int) {
+	if n.cursor == len(n.name) {
+		if len(n.name) >= MaxName {
+			return
+		}
+		n.name = append(n.name, rune(nameLetters[0]))
+		if dir > 0 {
+			return
+		}
+	}
+
+	i := Max(strings.IndexRune(nameLetters, n.name[n.cursor]), 0)
+	i = (i + dir + len(nameLetters)) % len(nameLetters)
+	n.name[n.cursor] = rune(nameLetters[i])
+}
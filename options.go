@@ -18,10 +18,12 @@ func (t toggle) String() string {
 
 type Options struct {
 	Selector
+	controls Controls
 }
 
 func (o *Options) Init() {
 	o.Selector.Init(smallFont, o.event)
+	o.controls.Init()
 }
 
 func (o *Options) Run() {
@@ -30,22 +32,24 @@ func (o *Options) Run() {
 	config.Save()
 }
 
-func (o *Options) event(ev sdl.Event) {
+func (o *Options) event(ev sdl.Event, actions []ActionEvent) {
+	if o.cursor != 3 {
+		for _, a := range actions {
+			if a.Down && a.Action == ActionConfirm {
+				o.toggle()
+			}
+		}
+	}
+
 	switch ev := ev.(type) {
 	case sdl.KeyDownEvent:
-		switch o.cursor {
-		case 3:
+		if o.cursor == 3 {
 			switch ev.Sym {
 			case sdl.K_BACKSPACE:
 				o.updateName("", true)
 			case sdl.K_SPACE:
 				o.updateName(" ", false)
 			}
-		default:
-			switch ev.Sym {
-			case sdl.K_RETURN, sdl.K_KP_ENTER, sdl.K_SPACE:
-				o.toggle()
-			}
 		}
 	case sdl.TextInputEvent:
 		if o.cursor == 3 {
@@ -68,6 +72,7 @@ func (o *Options) refresh() {
 		fmt.Sprint("Music: ", toggle(c.Music)),
 		fmt.Sprint("Player Name: ", c.Name),
 		fmt.Sprint("Invincibility: ", toggle(c.Invincibility)),
+		"Controls",
 	}
 }
 
@@ -83,6 +88,8 @@ func (o *Options) toggle() {
 		song.Play()
 	case 4:
 		c.Invincibility = !c.Invincibility
+	case 5:
+		o.controls.Run()
 	}
 }
 
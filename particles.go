@@ -4,21 +4,58 @@ import (
 	"math/rand"
 
 	"github.com/qeedquan/go-media/sdl"
-	"github.com/qeedquan/go-media/sdl/sdlgfx"
-	"github.com/qeedquan/go-media/sdl/sdlimage/sdlcolor"
 )
 
+const (
+	MaxParticles    = 1024 // preallocated, the pool grows past it if needed
+	MaxParticleSize = 11
+)
+
+// Particles of the same color share a row of pre-rendered dots in the
+// ensemble's sprite sheet, one per size. Explosions pick from a range of
+// shades instead of an exact color.
+const (
+	ParticleBlood = iota
+	ParticleDebris
+	ParticleWood
+	ParticleWater
+	ParticleSteam
+	ParticleFire
+	ParticleTrace
+	ParticleExplosion
+
+	explosionShades = 8
+	particleClasses = ParticleExplosion + explosionShades
+)
+
+var particleColors = [ParticleExplosion]sdl.Color{
+	ParticleBlood:  {230, 30, 20, 255},
+	ParticleDebris: {90, 90, 90, 255},
+	ParticleWood:   {148, 69, 6, 255},
+	ParticleWater:  {20, 60, 180, 255},
+	ParticleSteam:  {240, 240, 240, 255},
+	ParticleFire:   {255, 210, 170, 255},
+	ParticleTrace:  {170, 170, 170, 255},
+}
+
+func particleColor(class int) sdl.Color {
+	if class < ParticleExplosion {
+		return particleColors[class]
+	}
+	shade := class - ParticleExplosion
+	return sdl.Color{230, uint8(30 + (2*shade+1)*100/explosionShades), 20, 255}
+}
+
 type Particle struct {
 	Pos        Point
 	Vel        Point
-	Color      sdl.Color
+	Class      int
 	Accel      Point
 	Size       int
 	Initial    int
 	Life       int
 	Opacity    float64
 	Underwater bool
-	image      *Image
 }
 
 func (p *Particle) Update() {
@@ -33,161 +70,165 @@ func (p *Particle) Update() {
 	}
 }
 
-func (p *Particle) Draw() {
-	p.image.Bind()
-
-	screen.SetDrawColor(sdlcolor.Transparent)
-	screen.Clear()
-
-	x := p.Size / 2
-	y := x
-	r := p.Size / 2
-	p.image.SetAlphaMod(uint8(float64(p.Life) * 255 * p.Opacity / float64(p.Initial)))
-	sdlgfx.FilledEllipse(screen.Renderer, x, y, r, r, p.Color)
-
-	p.image.Unbind()
-	p.image.Blit(p.Pos)
-}
-
-func (p *Particle) Free() {
-	p.image.Destroy()
+// Sprite returns where the particle's dot is in the sprite sheet and where
+// it goes on screen.
+func (p *Particle) Sprite() Sprite {
+	const cell = MaxParticleSize + 1
+	size := int32(p.Size)
+	return Sprite{
+		Src:   sdl.Rect{int32((p.Size - 1) * cell), int32(p.Class * cell), size, size},
+		Dst:   sdl.Rect{int32(p.Pos.X), int32(p.Pos.Y), size, size},
+		Alpha: uint8(float64(p.Life) * 255 * p.Opacity / float64(p.Initial)),
+	}
 }
 
+// Ensemble keeps the live particles packed at the front of a pool that is
+// reused from game to game, and draws them all from one sprite sheet.
 type Ensemble struct {
-	image     *Image
+	sheet     *Image
 	particles []Particle
+	sprites   []Sprite
+	rng       *rand.Rand
+}
+
+func (e *Ensemble) add(p Particle) {
+	p.Life = p.Initial
+	e.particles = append(e.particles, p)
 }
 
 func (e *Ensemble) Blood(pos Point) {
 	p := Particle{
 		Pos:        pos,
-		Vel:        Point{rand.Float64()*5 - 2.5, rand.Float64()*5 - 2.5},
-		Color:      sdl.Color{230, 30, 20, 255},
+		Vel:        Point{e.rng.Float64()*5 - 2.5, e.rng.Float64()*5 - 2.5},
+		Class:      ParticleBlood,
 		Accel:      Point{0, 0.7},
-		Size:       rand.Intn(5) + 1,
-		Initial:    rand.Intn(30),
+		Size:       e.rng.Intn(5) + 1,
+		Initial:    e.rng.Intn(30),
 		Opacity:    1,
 		Underwater: true,
 	}
-	p.image = NewImage(p.Size, p.Size)
-	p.Life = p.Initial
-	e.particles = append(e.particles, p)
+	e.add(p)
 }
 
 func (e *Ensemble) Explosion(pos Point) {
 	p := Particle{
 		Pos:        pos,
-		Vel:        Point{rand.Float64()*5 - 2.5, rand.Float64()*5 - 2.5},
-		Color:      sdl.Color{230, 30 + uint8(rand.Intn(200)), 20, 255},
+		Vel:        Point{e.rng.Float64()*5 - 2.5, e.rng.Float64()*5 - 2.5},
+		Class:      ParticleExplosion + e.rng.Intn(200)*explosionShades/200,
 		Accel:      Point{0, 0.2},
-		Size:       rand.Intn(7) + 1,
-		Initial:    rand.Intn(30),
+		Size:       e.rng.Intn(7) + 1,
+		Initial:    e.rng.Intn(30),
 		Opacity:    1,
 		Underwater: true,
 	}
-	p.image = NewImage(p.Size, p.Size)
-	p.Life = p.Initial
-	e.particles = append(e.particles, p)
+	e.add(p)
 }
 
 func (e *Ensemble) Water(pos Point) {
 	p := Particle{
 		Pos:        pos,
-		Vel:        Point{rand.Float64()*5 - 2.5, -rand.Float64()*2.5 - 2},
-		Color:      sdl.Color{20, 60, 180, 255},
+		Vel:        Point{e.rng.Float64()*5 - 2.5, -e.rng.Float64()*2.5 - 2},
+		Class:      ParticleWater,
 		Accel:      Point{0, 0.3},
-		Size:       rand.Intn(5) + 1,
-		Initial:    rand.Intn(30),
+		Size:       e.rng.Intn(5) + 1,
+		Initial:    e.rng.Intn(30),
 		Opacity:    0.5,
 		Underwater: false,
 	}
-	p.image = NewImage(p.Size, p.Size)
-	p.Life = p.Initial
-	e.particles = append(e.particles, p)
+	e.add(p)
 }
 
 func (e *Ensemble) Debris(pos Point) {
 	p := Particle{
 		Pos:        pos,
-		Vel:        Point{rand.Float64()*5 - 2.5, rand.Float64()*5 - 2.5},
-		Color:      sdl.Color{90, 90, 90, 255},
+		Vel:        Point{e.rng.Float64()*5 - 2.5, e.rng.Float64()*5 - 2.5},
+		Class:      ParticleDebris,
 		Accel:      Point{0, 0.2},
-		Size:       rand.Intn(7) + 1,
-		Initial:    rand.Intn(30),
+		Size:       e.rng.Intn(7) + 1,
+		Initial:    e.rng.Intn(30),
 		Opacity:    1,
 		Underwater: true,
 	}
-	p.image = NewImage(p.Size, p.Size)
-	p.Life = p.Initial
-	e.particles = append(e.particles, p)
+	e.add(p)
 }
 
 func (e *Ensemble) Wood(pos Point) {
 	p := Particle{
 		Pos:        pos,
-		Vel:        Point{rand.Float64()*5 - 2.5, rand.Float64()*5 - 2.5},
-		Color:      sdl.Color{148, 69, 6, 255},
+		Vel:        Point{e.rng.Float64()*5 - 2.5, e.rng.Float64()*5 - 2.5},
+		Class:      ParticleWood,
 		Accel:      Point{0, 0.2},
-		Size:       rand.Intn(7) + 1,
-		Initial:    rand.Intn(30),
+		Size:       e.rng.Intn(7) + 1,
+		Initial:    e.rng.Intn(30),
 		Opacity:    1,
 		Underwater: true,
 	}
-	p.image = NewImage(p.Size, p.Size)
-	p.Life = p.Initial
-	e.particles = append(e.particles, p)
+	e.add(p)
 }
 
 func (e *Ensemble) Steam(pos Point) {
 	p := Particle{
 		Pos:        pos,
-		Vel:        Point{-rand.Float64() * 0.3, -rand.Float64() * 0.1},
-		Color:      sdl.Color{240, 240, 240, 255},
+		Vel:        Point{-e.rng.Float64() * 0.3, -e.rng.Float64() * 0.1},
+		Class:      ParticleSteam,
 		Accel:      Point{-0.1, -0.00002},
-		Size:       rand.Intn(10) + 1,
-		Initial:    rand.Intn(30),
+		Size:       e.rng.Intn(10) + 1,
+		Initial:    e.rng.Intn(30),
 		Opacity:    0.5,
 		Underwater: true,
 	}
-	p.image = NewImage(p.Size, p.Size)
-	p.Life = p.Initial
-	e.particles = append(e.particles, p)
+	e.add(p)
 }
 
 func (e *Ensemble) Fire(pos Point) {
 	p := Particle{
 		Pos:        pos,
-		Vel:        Point{-rand.Float64() * 0.3, -rand.Float64() * 0.1},
-		Color:      sdl.Color{255, 210, 170, 255},
+		Vel:        Point{-e.rng.Float64() * 0.3, -e.rng.Float64() * 0.1},
+		Class:      ParticleFire,
 		Accel:      Point{-0.1, -0.00002},
-		Size:       rand.Intn(11) + 1,
-		Initial:    rand.Intn(30),
+		Size:       e.rng.Intn(11) + 1,
+		Initial:    e.rng.Intn(30),
 		Opacity:    0.4,
 		Underwater: false,
 	}
-	p.image = NewImage(p.Size, p.Size)
-	p.Life = p.Initial
-	e.particles = append(e.particles, p)
+	e.add(p)
 }
 
 func (e *Ensemble) Trace(pos Point) {
 	p := Particle{
 		Pos:        pos,
 		Vel:        Point{},
-		Color:      sdl.Color{170, 170, 170, 255},
+		Class:      ParticleTrace,
 		Accel:      Point{},
 		Size:       6,
-		Initial:    5 + rand.Intn(5),
-		Opacity:    0.1 + rand.Float64()*0.1,
+		Initial:    5 + e.rng.Intn(5),
+		Opacity:    0.1 + e.rng.Float64()*0.1,
 		Underwater: false,
 	}
-	p.image = NewImage(p.Size, p.Size)
-	p.Life = p.Initial
-	e.particles = append(e.particles, p)
+	e.add(p)
 }
 
 func (e *Ensemble) Init() {
-	e.image = NewImage(W, H)
+	const cell = MaxParticleSize + 1
+
+	e.particles = make([]Particle, 0, MaxParticles)
+	e.sprites = make([]Sprite, 0, MaxParticles)
+
+	e.sheet = NewImage(MaxParticleSize*cell, particleClasses*cell)
+	e.sheet.Bind()
+	for class := 0; class < particleClasses; class++ {
+		for size := 1; size <= MaxParticleSize; size++ {
+			x := (size-1)*cell + size/2
+			y := class*cell + size/2
+			screen.FilledEllipse(x, y, size/2, size/2, particleColor(class))
+		}
+	}
+	e.sheet.Unbind()
+}
+
+func (e *Ensemble) Reset(rng *rand.Rand) {
+	e.Free()
+	e.rng = rng
 }
 
 func (e *Ensemble) Update() {
@@ -195,7 +236,6 @@ func (e *Ensemble) Update() {
 		p := &e.particles[i]
 		p.Update()
 		if p.Life <= 0 {
-			p.Free()
 			l := len(e.particles) - 1
 			e.particles[i], e.particles = e.particles[l], e.particles[:l]
 		} else {
@@ -209,15 +249,16 @@ func (e *Ensemble) Draw() {
 		return
 	}
 
+	e.sprites = e.sprites[:0]
 	for i := range e.particles {
 		p := &e.particles[i]
-		p.Draw()
+		if p.Life > 0 {
+			e.sprites = append(e.sprites, p.Sprite())
+		}
 	}
+	screen.BlitBatch(e.sheet.Texture, e.sprites)
 }
 
 func (e *Ensemble) Free() {
-	for _, p := range e.particles {
-		p.Free()
-	}
 	e.particles = e.particles[:0]
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/qeedquan/go-media/sdl"
+	"github.com/qeedquan/go-media/sdl/sdlimage/sdlcolor"
+)
+
+// batchCounter is a headless renderer that counts the batches drawn.
+type batchCounter struct {
+	nullRenderer
+	batches, sprites int
+}
+
+func (c *batchCounter) BlitBatch(t Texture, sprites []Sprite) {
+	c.batches++
+	c.sprites += len(sprites)
+}
+
+// titanicFight fills the ensemble with the particles of a long fight.
+func titanicFight(e *Ensemble, n int) {
+	e.Reset(rand.New(rand.NewSource(1)))
+	rng := rand.New(rand.NewSource(2))
+	spawn := []func(Point){e.Explosion, e.Debris, e.Wood, e.Steam, e.Fire, e.Water, e.Blood, e.Trace}
+	for i := 0; i < n; i++ {
+		p := Point{rng.Float64() * W, rng.Float64() * H}
+		spawn[i%len(spawn)](p)
+	}
+	for i := range e.particles {
+		p := &e.particles[i]
+		p.Initial = Max(p.Initial, 1)
+		p.Life = p.Initial
+	}
+}
+
+func TestEnsembleDrawsOneBatch(t *testing.T) {
+	defer func(s Renderer, p bool) { screen, config.Particles = s, p }(screen, config.Particles)
+	c := &batchCounter{}
+	screen = c
+	config.Particles = true
+
+	var e Ensemble
+	e.Init()
+	for _, n := range []int{1, 10, 500} {
+		titanicFight(&e, n)
+		c.batches, c.sprites = 0, 0
+		e.Draw()
+		if c.batches != 1 || c.sprites != n {
+			t.Errorf("%v particles drawn in %v batches of %v sprites, want 1 of %v", n, c.batches, c.sprites, n)
+		}
+	}
+}
+
+// drawEllipses is how particles were drawn before the sprite sheet: each
+// one redrew its dot into a texture of its own every frame.
+func drawEllipses(images []*Image, particles []Particle) {
+	for i := range particles {
+		p := &particles[i]
+		m := images[i]
+		m.Bind()
+		screen.SetDrawColor(sdlcolor.Transparent)
+		screen.Clear()
+		r := p.Size / 2
+		m.SetAlphaMod(p.Sprite().Alpha)
+		screen.FilledEllipse(r, r, r, r, particleColor(p.Class))
+		m.Unbind()
+		m.Blit(p.Pos)
+	}
+}
+
+// BenchmarkEnsembleDraw draws a frame of 500 particles from the sprite
+// sheet and, for comparison, the way they were drawn before.
+func BenchmarkEnsembleDraw(b *testing.B) {
+	benchmarkDisplay(b)
+	defer func(p bool) { config.Particles = p }(config.Particles)
+	config.Particles = true
+
+	var e Ensemble
+	e.Init()
+	titanicFight(&e, 500)
+
+	b.Run("sheet", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			screen.SetDrawColor(sdl.Color{})
+			screen.Clear()
+			e.Draw()
+			screen.Present()
+		}
+	})
+
+	b.Run("ellipses", func(b *testing.B) {
+		images := make([]*Image, len(e.particles))
+		for i, p := range e.particles {
+			images[i] = NewImage(p.Size, p.Size)
+		}
+		defer func() {
+			for _, m := range images {
+				m.Destroy()
+			}
+		}()
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			screen.SetDrawColor(sdl.Color{})
+			screen.Clear()
+			drawEllipses(images, e.particles)
+			screen.Present()
+		}
+	})
+}
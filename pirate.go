@@ -2,6 +2,18 @@ package main
 
 type Pirate struct {
 	Boat
+	Stationary bool
+}
+
+func init() {
+	RegisterActor("pirate", ActorKind{New: newPirate, Variants: []string{"stationary"}})
+}
+
+func newPirate(g *Game, ev SpawnEvent) Actor {
+	p := &Pirate{}
+	p.Init()
+	p.Stationary = ev.Variant == "stationary"
+	return p
 }
 
 func (p *Pirate) Init() {
@@ -13,10 +25,61 @@ func (p *Pirate) Init() {
 	p.Images = []*Image{i}
 	p.Sound = LoadSound("blub")
 	p.Life = 2
+	p.Buoyancy = LoadBuoyancy("pirate")
 	p.Pos = Point{W, WaterLevel(W)}
 	p.Vel = Point{-1, 0}
 }
 
-func (p *Pirate) Update() {
-	UpdateEnemyBoat(&p.Entity, 0.9, 2, 0.25, 1, false)
+func (p *Pirate) Body() *Entity { return &p.Entity }
+func (p *Pirate) Layer() Layer  { return LayerEnemy }
+
+func (p *Pirate) Update(g *Game) {
+	UpdateEnemyBoat(&p.Boat, p.Stationary)
+
+	m := p.Image()
+	center := Point{p.Pos.CenterX(m), p.Pos.CenterY(m)}
+	if p.T%50 == 0 && !p.Dying {
+		var c Cannon
+
+		pos := Point{p.Pos.X, p.Pos.CenterY(m)}
+		c.Init(pos, g.player.Angle, true, false)
+		g.enemyCannons = append(g.enemyCannons, c)
+
+		pt := p.Rotate(Point{0, 10}).Add(center)
+		for i := 0; i < 4; i++ {
+			g.ensemble.Fire(pt)
+		}
+	} else if p.Dying {
+		g.ensemble.Explosion(center)
+		g.ensemble.Wood(center)
+	}
+}
+
+func (p *Pirate) Gone() bool {
+	return p.Pos.Right(p.Image()) < 0 || p.Dead
+}
+
+func (p *Pirate) Touch(g *Game) {}
+
+func (p *Pirate) Hit(g *Game, c *Cannon) bool {
+	snd := LoadSound("poks")
+	snd.Play(0)
+
+	p.Damage(1)
+
+	pl := &g.player
+	for i := 0; i < 6; i++ {
+		pt := Point{
+			pl.Pos.CenterX(pl.Image()),
+			pl.Pos.CenterY(pl.Image()),
+		}
+		pt.X += g.rng.Float64() * 15
+		pt.Y += g.rng.Float64()*30 - 10
+		g.ensemble.Wood(pt)
+	}
+	return true
+}
+
+func (p *Pirate) Value(c *Cannon) int {
+	return 25
 }
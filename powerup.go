@@ -5,6 +5,18 @@ type Powerup struct {
 	Picked bool
 	Fading bool
 	Fade   int
+
+	Buoyancy Buoyancy
+}
+
+func init() {
+	RegisterActor("powerup", ActorKind{New: newPowerup})
+}
+
+func newPowerup(g *Game, ev SpawnEvent) Actor {
+	p := &Powerup{}
+	p.Init()
+	return p
 }
 
 func (p *Powerup) Init() {
@@ -12,13 +24,29 @@ func (p *Powerup) Init() {
 	p.Images = []*Image{p.Pictures[0].Copy()}
 	p.Pos = Point{W, WaterLevel(W)}
 	p.Vel = Point{-1, 0}
+	p.Buoyancy = LoadBuoyancy("powerup")
 	p.Picked = false
 	p.Fading = false
 }
 
-func (p *Powerup) Update() {
-	m := p.Image()
-	l := WaterLevel(p.Pos.CenterX(m))
+func (p *Powerup) Body() *Entity { return &p.Entity }
+func (p *Powerup) Layer() Layer  { return LayerPickup }
+
+func (p *Powerup) Gone() bool {
+	return p.Picked
+}
+
+func (p *Powerup) Touch(g *Game) {
+	if !p.Fading && Collision(&g.player.Entity, &p.Entity) {
+		g.health.Add()
+		p.Pickup()
+	}
+}
+
+func (p *Powerup) Hit(g *Game, c *Cannon) bool { return false }
+func (p *Powerup) Value(c *Cannon) int         { return 0 }
+
+func (p *Powerup) Update(g *Game) {
 	if p.Fading {
 		if p.Fade > 0 {
 			p.Fade--
@@ -27,18 +55,7 @@ func (p *Powerup) Update() {
 		}
 	}
 
-	bottom := p.Pos.Bottom(m)
-	if bottom > l {
-		p.Vel.Y *= 0.8
-		if p.Pos.X > l {
-			p.Vel.Y -= 2
-		} else {
-			p.Vel.Y -= 0.25 * (bottom - l)
-		}
-	}
-
-	p.Vel.Y++
-	p.Pos = p.Pos.Add(p.Vel)
+	p.Buoyancy.Float(&p.Entity)
 }
 
 func (p *Powerup) Pickup() {
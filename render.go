@@ -0,0 +1,39 @@
+package main
+
+import (
+	"image"
+
+	"github.com/qeedquan/go-media/sdl"
+	"github.com/qeedquan/go-media/sdl/sdlttf"
+)
+
+// Renderer is everything the game needs from the drawing side. The
+// simulation never draws on its own; it only creates textures through
+// Image, so a headless renderer is enough to step a Game.
+type Renderer interface {
+	NewTexture(w, h int) (Texture, error)
+	LoadTexture(img image.Image) (Texture, error)
+	SetTarget(t Texture) error
+	SetDrawColor(c sdl.Color)
+	Clear()
+	DrawLine(x1, y1, x2, y2 int)
+	FillRect(r sdl.Rect)
+	FilledEllipse(x, y, rx, ry int, c sdl.Color)
+	Blit(t Texture, dst sdl.Rect, angle float64)
+	BlitBatch(t Texture, sprites []Sprite)
+	FillGeometry(vertices []sdl.Vertex, indices []int32)
+	RenderText(font *sdlttf.Font, c sdl.Color, text string) (Texture, int, int, error)
+	Present()
+}
+
+// Sprite is a part of a texture drawn unrotated with its own opacity.
+type Sprite struct {
+	Src   sdl.Rect
+	Dst   sdl.Rect
+	Alpha uint8
+}
+
+type Texture interface {
+	SetAlphaMod(a uint8)
+	Destroy()
+}
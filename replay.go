@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// Input is the set of player actions for a single frame. Left and right
+// are held across frames, the others are only set on the frame they were
+// pressed.
+type Input uint8
+
+const (
+	InputLeft Input = 1 << iota
+	InputRight
+	InputJump
+	InputFire
+	InputPause
+	InputCharge
+
+	inputHeld = InputLeft | InputRight | InputCharge
+)
+
+var actionInputs = [NumActions]Input{
+	ActionMoveLeft:  InputLeft,
+	ActionMoveRight: InputRight,
+	ActionJump:      InputJump,
+	ActionFire:      InputFire,
+	ActionPause:     InputPause,
+}
+
+const (
+	replayMagic   = "FBRP"
+	replayVersion = 3
+
+	// MaxReplayFrames bounds what a replay file can claim, a day of play
+	MaxReplayFrames = 24 * 60 * 60 * Fps
+)
+
+// Assists are the cheats a session was recorded with. They change how the
+// game plays out, so playback has to use the recorded ones.
+type Assists uint64
+
+const (
+	AssistInvincible Assists = 1 << iota
+)
+
+// Replay is a recorded session: the seed, map and assists it was started
+// with and the input for every frame that was stepped.
+type Replay struct {
+	Seed    int64
+	Map     string
+	Assists Assists
+	Inputs  []Input
+}
+
+func (r *Replay) Record(in Input) {
+	r.Inputs = append(r.Inputs, in)
+}
+
+func (r *Replay) Input(frame int) (in Input, ok bool) {
+	if frame >= len(r.Inputs) {
+		return 0, false
+	}
+	return r.Inputs[frame], true
+}
+
+func (r *Replay) write(w io.Writer) error {
+	var buf []byte
+
+	buf = append(buf, replayMagic...)
+	buf = binary.AppendUvarint(buf, replayVersion)
+	buf = binary.AppendVarint(buf, r.Seed)
+	buf = binary.AppendUvarint(buf, uint64(len(r.Map)))
+	buf = append(buf, r.Map...)
+	buf = binary.AppendUvarint(buf, uint64(r.Assists))
+
+	// inputs rarely change from one frame to the next, so store them as
+	// (run length, input) pairs
+	var runs []byte
+	nruns := uint64(0)
+	for i := 0; i < len(r.Inputs); {
+		j := i + 1
+		for j < len(r.Inputs) && r.Inputs[j] == r.Inputs[i] {
+			j++
+		}
+		runs = binary.AppendUvarint(runs, uint64(j-i))
+		runs = append(runs, byte(r.Inputs[i]))
+		nruns++
+		i = j
+	}
+	buf = binary.AppendUvarint(buf, nruns)
+	buf = append(buf, runs...)
+
+	_, err := w.Write(buf)
+	return err
+}
+
+func (r *Replay) read(rd io.Reader) error {
+	br := bufio.NewReader(rd)
+
+	var magic [len(replayMagic)]byte
+	if _, err := io.ReadFull(br, magic[:]); err != nil {
+		return err
+	}
+	if string(magic[:]) != replayMagic {
+		return errors.New("not a replay file")
+	}
+
+	version, err := binary.ReadUvarint(br)
+	if err != nil {
+		return err
+	}
+	if version == 0 || version > replayVersion {
+		return fmt.Errorf("unsupported replay version %v", version)
+	}
+
+	r.Seed, err = binary.ReadVarint(br)
+	if err != nil {
+		return err
+	}
+
+	// version 1 only knew the two built in maps
+	if version == 1 {
+		mode, err := br.ReadByte()
+		if err != nil {
+			return err
+		}
+		r.Map = "story"
+		if mode != 0 {
+			r.Map = "endless"
+		}
+	} else {
+		n, err := binary.ReadUvarint(br)
+		if err != nil {
+			return err
+		}
+		if n > 4096 {
+			return errors.New("map name too long")
+		}
+		name := make([]byte, n)
+		if _, err := io.ReadFull(br, name); err != nil {
+			return err
+		}
+		r.Map = string(name)
+	}
+
+	// assists were only recorded from version 3 on
+	r.Assists = 0
+	if version >= 3 {
+		assists, err := binary.ReadUvarint(br)
+		if err != nil {
+			return err
+		}
+		r.Assists = Assists(assists)
+	}
+
+	nruns, err := binary.ReadUvarint(br)
+	if err != nil {
+		return err
+	}
+
+	if nruns > MaxReplayFrames {
+		return errors.New("too many frames")
+	}
+
+	r.Inputs = r.Inputs[:0]
+	for i := uint64(0); i < nruns; i++ {
+		n, err := binary.ReadUvarint(br)
+		if err != nil {
+			return err
+		}
+		if n > MaxReplayFrames-uint64(len(r.Inputs)) {
+			return errors.New("too many frames")
+		}
+		in, err := br.ReadByte()
+		if err != nil {
+			return err
+		}
+		for ; n > 0; n-- {
+			r.Inputs = append(r.Inputs, Input(in))
+		}
+	}
+
+	return nil
+}
+
+// Save writes the replay to the next free file in the replay directory and
+// returns the name it was written to, or an empty string on failure.
+func (r *Replay) Save() (filename string) {
+	var err error
+
+	log.SetPrefix("replay: ")
+	defer func() {
+		if err != nil {
+			log.Print("save failure: ", err)
+			filename = ""
+		} else {
+			log.Printf("saved to %q", filename)
+		}
+	}()
+
+	path, err := config.Path()
+	if err != nil {
+		return
+	}
+
+	filename, err = nextFilename(filepath.Join(path, "replays"), "replay_", ".fbr")
+	if err != nil {
+		return
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return
+	}
+
+	w := bufio.NewWriter(f)
+	err = r.write(w)
+	flushErr := w.Flush()
+	closeErr := f.Close()
+
+	if err == nil {
+		err = flushErr
+	}
+	if err == nil {
+		err = closeErr
+	}
+	return
+}
+
+func LoadReplay(filename string) (*Replay, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := &Replay{}
+	err = r.read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+	return r, nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestReplayRoundTrip(t *testing.T) {
+	tests := []*Replay{
+		{Seed: 1, Map: "story"},
+		{Seed: -42, Map: "endless", Inputs: []Input{0, 0, InputFire, InputLeft, InputLeft | InputJump}},
+		{Seed: 7, Map: "data/maps/convoy.json", Assists: AssistInvincible, Inputs: []Input{InputRight, InputRight}},
+	}
+
+	for _, want := range tests {
+		buf := new(bytes.Buffer)
+		if err := want.write(buf); err != nil {
+			t.Fatal(err)
+		}
+		got := new(Replay)
+		if err := got.read(buf); err != nil {
+			t.Fatalf("%+v: %v", want, err)
+		}
+		if len(want.Inputs) == 0 {
+			want.Inputs = got.Inputs[:0]
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("read %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestReplayVersion2HasNoAssists(t *testing.T) {
+	var buf []byte
+	buf = append(buf, replayMagic...)
+	buf = binary.AppendUvarint(buf, 2)
+	buf = binary.AppendVarint(buf, 5)
+	buf = binary.AppendUvarint(buf, uint64(len("story")))
+	buf = append(buf, "story"...)
+	buf = binary.AppendUvarint(buf, 1)
+	buf = binary.AppendUvarint(buf, 3)
+	buf = append(buf, byte(InputFire))
+
+	r := &Replay{Assists: AssistInvincible}
+	if err := r.read(bytes.NewReader(buf)); err != nil {
+		t.Fatal(err)
+	}
+	want := &Replay{Seed: 5, Map: "story", Inputs: []Input{InputFire, InputFire, InputFire}}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("read %+v, want %+v", r, want)
+	}
+}
+
+// TestReplayKeepsAssists plays a session recorded while invincible back
+// with invincibility switched off. The player is hit on the way, which
+// draws from the random numbers only when not invincible.
+func TestReplayKeepsAssists(t *testing.T) {
+	InitHeadless()
+	defer func(i bool) { config.Invincibility = i }(config.Invincibility)
+
+	m, err := LoadMap("story")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const frames = 3000
+	config.Invincibility = true
+	var g Game
+	g.Init()
+	g.Reset(m, 3)
+	for n := 0; n < frames && !g.Done; n++ {
+		if n%7 == 0 {
+			g.input |= InputFire
+		}
+		g.Step()
+	}
+	recorded := g.Replay()
+	score := g.score.Value
+	if recorded.Assists != AssistInvincible {
+		t.Fatalf("recorded assists %v, want %v", recorded.Assists, AssistInvincible)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := recorded.write(buf); err != nil {
+		t.Fatal(err)
+	}
+	replay := new(Replay)
+	if err := replay.read(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	config.Invincibility = false
+	g.Rewind(m, replay)
+	for n := 0; n < frames && !g.Done; n++ {
+		g.Step()
+	}
+	if g.score.Value != score {
+		t.Errorf("playback scored %v, the session %v", g.score.Value, score)
+	}
+	if g.player.Dead || g.health.Life != MaxHearts {
+		t.Errorf("player was hurt in playback, life %v", g.health.Life)
+	}
+	if r := g.Result(); !r.Invincible {
+		t.Error("playback result is not marked invincible")
+	}
+}
+
+func TestReplayRejectsTooManyFrames(t *testing.T) {
+	encode := func(nruns uint64, lengths ...uint64) []byte {
+		var buf []byte
+		buf = append(buf, replayMagic...)
+		buf = binary.AppendUvarint(buf, replayVersion)
+		buf = binary.AppendVarint(buf, 1)
+		buf = binary.AppendUvarint(buf, uint64(len("story")))
+		buf = append(buf, "story"...)
+		buf = binary.AppendUvarint(buf, 0)
+		buf = binary.AppendUvarint(buf, nruns)
+		for _, n := range lengths {
+			buf = binary.AppendUvarint(buf, n)
+			buf = append(buf, 0)
+		}
+		return buf
+	}
+
+	tests := []struct {
+		name string
+		buf  []byte
+		ok   bool
+	}{
+		{"run count", encode(1 << 62), false},
+		{"run length", encode(1, 1<<64-1), false},
+		{"total", encode(3, MaxReplayFrames/2, MaxReplayFrames/2, 1), false},
+		{"longest", encode(2, MaxReplayFrames/2, MaxReplayFrames/2), true},
+	}
+	for _, tt := range tests {
+		r := new(Replay)
+		err := r.read(bytes.NewReader(tt.buf))
+		switch {
+		case tt.ok && err != nil:
+			t.Errorf("%v: %v", tt.name, err)
+		case tt.ok && len(r.Inputs) != MaxReplayFrames:
+			t.Errorf("%v: read %v frames, want %v", tt.name, len(r.Inputs), MaxReplayFrames)
+		case !tt.ok && err == nil:
+			t.Errorf("%v: read a replay over %v frames", tt.name, MaxReplayFrames)
+		}
+	}
+}
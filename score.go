@@ -2,8 +2,6 @@ package main
 
 import (
 	"fmt"
-
-	"github.com/qeedquan/go-media/sdl/sdlimage/sdlcolor"
 )
 
 type Score struct {
@@ -19,7 +17,7 @@ func (s *Score) Reset() {
 }
 
 func (s *Score) Draw() {
-	blitText(smallFont, int(s.pos.X), int(s.pos.Y), sdlcolor.Black, fmt.Sprintf("Score: %v", s.Value))
+	DrawText(Small(), int(s.pos.X), int(s.pos.Y), fmt.Sprintf("Score: %v", s.Value))
 }
 
 func (s *Score) Update() {
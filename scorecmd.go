@@ -0,0 +1,474 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const scoresUsage = `usage: funnyboat [options] scores command [arguments]
+
+commands:
+  list [-mode m]                           print the tables
+  export [-mode m] [-format f] [-o file]   write the entries as csv or json
+  import [-mode m] [-format f] file...     replace tables with the entries of the files
+  merge [-mode m] [-format f] file...      add the entries of the files to the tables
+  reset (-mode m | -all)                   empty tables
+
+A mode is story, endless or the name of a campaign. The files import and
+merge read are exports, in csv or json, or score tables copied from
+another config directory.
+`
+
+// csvColumns are the columns of exported csv files. Imported ones need
+// the name and score, the others may be left out.
+var csvColumns = []string{"mode", "name", "score", "date", "frames", "phase", "seed", "invincible", "replay"}
+
+// ScoresCommand runs a scores subcommand on the tables in the config
+// directory without opening a window.
+func ScoresCommand(args []string) {
+	log.SetPrefix("scores: ")
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, scoresUsage)
+		os.Exit(2)
+	}
+
+	cmd, args := args[0], args[1:]
+	fs := flag.NewFlagSet("scores "+cmd, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, scoresUsage)
+	}
+	mode := fs.String("mode", "", "only the table of this map")
+	format := fs.String("format", "", "csv or json, by default picked from the file extension")
+
+	var err error
+	switch cmd {
+	case "list":
+		fs.Parse(args)
+		err = listScores(os.Stdout, *mode)
+	case "export":
+		out := fs.String("o", "", "write to this file instead of standard output")
+		fs.Parse(args)
+		err = exportScores(*out, *format, *mode)
+	case "import", "merge":
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			log.Fatalf("%v: no files given", cmd)
+		}
+		err = importScores(fs.Args(), *format, *mode, cmd == "merge")
+	case "reset":
+		all := fs.Bool("all", false, "empty every table")
+		fs.Parse(args)
+		switch {
+		case *mode != "" && *all:
+			log.Fatal("reset: give either -mode or -all, not both")
+		case *mode == "" && !*all:
+			log.Fatal("reset: give the table to empty with -mode, or -all")
+		}
+		err = resetScores(*mode)
+	default:
+		fmt.Fprint(os.Stderr, scoresUsage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// storedScores loads the tables scoreTables names. Unlike in the game,
+// tables missing on disk are empty and unreadable ones are an error.
+func storedScores(mode string) ([]*ScoreTable, error) {
+	tables, err := scoreTables(mode)
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range tables {
+		tables[i], err = storedTable(t.Mode, t.filename)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// storedTable reads a table, leaving out the placeholders of a table
+// missing on disk.
+func storedTable(mode, file string) (*ScoreTable, error) {
+	t, err := readScoreTable(mode, file)
+	if os.IsNotExist(err) {
+		t.Clear()
+		return t, nil
+	}
+	return t, err
+}
+
+// scoreTables returns empty tables for the mode, or when no mode is given
+// for the story and endless and the campaigns tables are saved for.
+// Campaign tables are known by the files found, as a relative map path
+// may hash to another file from here.
+func scoreTables(mode string) ([]*ScoreTable, error) {
+	if mode != "" {
+		return []*ScoreTable{{Mode: mode, filename: ScoreFile(mode)}}, nil
+	}
+
+	path, err := config.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []*ScoreTable
+	for _, mode := range []string{"story", "endless"} {
+		tables = append(tables, &ScoreTable{Mode: mode, filename: ScoreFile(mode)})
+	}
+	matches, _ := filepath.Glob(filepath.Join(path, "scores_*.json"))
+	for _, name := range matches {
+		mode, err := scoresMode(name)
+		if err != nil {
+			log.Printf("skipping %v: %v", name, err)
+			continue
+		}
+		file := strings.TrimSuffix(filepath.Base(name), ".json")
+		tables = append(tables, &ScoreTable{Mode: mode, filename: file})
+	}
+	return tables, nil
+}
+
+// scoresMode returns the map a table file was saved for.
+func scoresMode(filename string) (string, error) {
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	var f struct {
+		Mode string `json:"mode"`
+	}
+	err = json.Unmarshal(buf, &f)
+	if err != nil {
+		return "", err
+	}
+	if f.Mode == "" {
+		return "", errors.New("missing mode")
+	}
+	return f.Mode, nil
+}
+
+// entries returns the entries of both tables, honest ones first, with the
+// mode filled in for those that predate it.
+func (t *ScoreTable) entries() []Rank {
+	var ranks []Rank
+	for _, table := range []RankSlice{t.Ranks, t.Assisted} {
+		for _, r := range table {
+			if r.Mode == "" {
+				r.Mode = t.Mode
+			}
+			ranks = append(ranks, r)
+		}
+	}
+	return ranks
+}
+
+func listScores(w io.Writer, mode string) error {
+	tables, err := storedScores(mode)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODE\tTABLE\tRANK\tNAME\tSCORE\tDATE\tPHASE\tDURATION\tREPLAY")
+	for _, t := range tables {
+		for _, assisted := range []bool{false, true} {
+			table := "unassisted"
+			if assisted {
+				table = "assisted"
+			}
+			for i, r := range *t.Table(assisted) {
+				date, phase := "", ""
+				if r.Date != nil {
+					date = r.Date.Local().Format("2006-01-02 15:04")
+				}
+				if r.Phase > 0 {
+					phase = fmt.Sprint(r.Phase)
+				}
+				fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+					t.Mode, table, i+1, r.Name, r.Value, date, phase, r.Clock(), r.Replay)
+			}
+		}
+	}
+	return tw.Flush()
+}
+
+func exportScores(filename, format, mode string) (err error) {
+	if format == "" {
+		format = formatOf(filename)
+	}
+
+	tables, err := storedScores(mode)
+	if err != nil {
+		return err
+	}
+	ranks := []Rank{}
+	for _, t := range tables {
+		ranks = append(ranks, t.entries()...)
+	}
+
+	w := io.Writer(os.Stdout)
+	if filename != "" {
+		f, err := os.Create(filename)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if xerr := f.Close(); err == nil {
+				err = xerr
+			}
+		}()
+		w = f
+	}
+
+	switch format {
+	case "json":
+		buf, err := json.MarshalIndent(ranks, "", "\t")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(buf, '\n'))
+		return err
+	case "csv":
+		return writeCSV(w, ranks)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// formatOf picks the format of a file from its extension, defaulting to
+// json.
+func formatOf(filename string) string {
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		return "csv"
+	}
+	return "json"
+}
+
+func writeCSV(w io.Writer, ranks []Rank) error {
+	cw := csv.NewWriter(w)
+	cw.Write(csvColumns)
+	for _, r := range ranks {
+		date := ""
+		if r.Date != nil {
+			date = r.Date.Format(time.RFC3339Nano)
+		}
+		cw.Write([]string{
+			r.Mode,
+			r.Name,
+			fmt.Sprint(r.Value),
+			date,
+			fmt.Sprint(r.Frames),
+			fmt.Sprint(r.Phase),
+			fmt.Sprint(r.Seed),
+			fmt.Sprint(r.Invincible),
+			r.Replay,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// readScores reads an export or a score table file.
+func readScores(filename, format string) ([]Rank, error) {
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" {
+		format = formatOf(filename)
+	}
+
+	var ranks []Rank
+	switch format {
+	case "json":
+		buf = bytes.TrimSpace(buf)
+		if len(buf) > 0 && buf[0] == '{' {
+			var assisted []Rank
+			ranks, assisted, err = parseScores(buf)
+			ranks = append(ranks, assisted...)
+			break
+		}
+		dec := json.NewDecoder(bytes.NewReader(buf))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&ranks)
+	case "csv":
+		ranks, err = parseCSV(buf)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+	return ranks, nil
+}
+
+func parseCSV(buf []byte) ([]Rank, error) {
+	records, err := csv.NewReader(bytes.NewReader(buf)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("missing header")
+	}
+
+	columns := make(map[string]bool)
+	for _, c := range csvColumns {
+		columns[c] = true
+	}
+	header := records[0]
+	found := make(map[string]bool)
+	for _, c := range header {
+		if !columns[c] {
+			return nil, fmt.Errorf("unknown column %q", c)
+		}
+		found[c] = true
+	}
+	if !found["name"] || !found["score"] {
+		return nil, errors.New("the name and score columns are required")
+	}
+
+	var ranks []Rank
+	for n, record := range records[1:] {
+		var r Rank
+		for i, c := range header {
+			v := strings.TrimSpace(record[i])
+			switch {
+			case v == "" && (c == "name" || c == "score"):
+				err = errors.New("missing value")
+			case c == "mode":
+				r.Mode = v
+			case c == "name":
+				r.Name = v
+			case c == "replay":
+				r.Replay = v
+			case v == "":
+			case c == "score":
+				r.Value, err = strconv.Atoi(v)
+			case c == "date":
+				var d time.Time
+				d, err = time.Parse(time.RFC3339, v)
+				r.Date = &d
+			case c == "frames":
+				r.Frames, err = strconv.Atoi(v)
+			case c == "phase":
+				r.Phase, err = strconv.Atoi(v)
+			case c == "seed":
+				r.Seed, err = strconv.ParseInt(v, 10, 64)
+			case c == "invincible":
+				r.Invincible, err = strconv.ParseBool(v)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("line %v: %v: %v", n+2, c, err)
+			}
+		}
+		ranks = append(ranks, r)
+	}
+	return ranks, nil
+}
+
+// importScores files the entries of the files in the tables of their
+// modes. Merging adds them to what is there, skipping runs the table
+// already has, importing replaces the tables the files have entries for.
+// Entries without a mode go to the given one, entries of other modes are
+// skipped when a mode is given.
+func importScores(files []string, format, mode string, merge bool) error {
+	var ranks []Rank
+	for _, name := range files {
+		r, err := readScores(name, format)
+		if err != nil {
+			return err
+		}
+		ranks = append(ranks, r...)
+	}
+
+	tables := make(map[string]*ScoreTable)
+	var order []*ScoreTable
+	added := make(map[*ScoreTable]int)
+	for i, r := range ranks {
+		switch {
+		case r.Mode == "" && mode == "":
+			return fmt.Errorf("entry %v (%q) has no mode, give one with -mode", i+1, r.Name)
+		case r.Mode == "":
+			r.Mode = mode
+		case mode != "" && ScoreFile(r.Mode) != ScoreFile(mode):
+			continue
+		}
+
+		t := tables[ScoreFile(r.Mode)]
+		if t == nil {
+			t = &ScoreTable{Mode: r.Mode, filename: ScoreFile(r.Mode)}
+			t.Clear()
+			if merge {
+				var err error
+				t, err = storedTable(r.Mode, t.filename)
+				if err != nil {
+					return err
+				}
+			}
+			tables[ScoreFile(r.Mode)] = t
+			order = append(order, t)
+		}
+		if merge && t.has(&r) {
+			continue
+		}
+		if t.Add(r) != nil {
+			added[t]++
+		}
+	}
+
+	for _, t := range order {
+		err := t.Save()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%v: %v entries added\n", t.Mode, added[t])
+	}
+	return nil
+}
+
+// has reports whether the table already has the run.
+func (t *ScoreTable) has(r *Rank) bool {
+	for _, o := range *t.Table(r.Assisted()) {
+		switch {
+		case o.Name != r.Name || o.Value != r.Value:
+		case o.Frames != r.Frames || o.Phase != r.Phase || o.Seed != r.Seed:
+		case (o.Date == nil) != (r.Date == nil):
+		case o.Date != nil && !o.Date.Equal(*r.Date):
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// resetScores empties the tables without reading them, so unreadable
+// ones can be reset too.
+func resetScores(mode string) error {
+	tables, err := scoreTables(mode)
+	if err != nil {
+		return err
+	}
+	for _, t := range tables {
+		t.Clear()
+		err := t.Save()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
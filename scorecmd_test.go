@@ -0,0 +1,294 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// entryKeys describes entries field by field, comparing dates by instant.
+func entryKeys(ranks []Rank) []string {
+	var keys []string
+	for _, r := range ranks {
+		date := ""
+		if r.Date != nil {
+			date = r.Date.UTC().Format(time.RFC3339Nano)
+		}
+		keys = append(keys, fmt.Sprintf("%v|%v|%v|%v|%v|%v|%v|%v|%v",
+			r.Mode, r.Name, r.Value, date, r.Frames, r.Phase, r.Seed, r.Invincible, r.Replay))
+	}
+	return keys
+}
+
+// fillScores replaces the tables of the entries' modes with them.
+func fillScores(t *testing.T, ranks []Rank) {
+	t.Helper()
+	tables := make(map[string]*ScoreTable)
+	for _, r := range ranks {
+		table := tables[r.Mode]
+		if table == nil {
+			table = LoadScores(r.Mode)
+			table.Clear()
+			tables[r.Mode] = table
+		}
+		table.Add(r)
+	}
+	for _, table := range tables {
+		if err := table.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	date := time.Date(2024, 5, 17, 21, 4, 5, 123456789, time.FixedZone("EEST", 3*60*60))
+	campaign := filepath.Join("maps", "convoy.json")
+	ranks := []Rank{
+		{Name: "Hectigo", Value: 1500, Mode: "story", Date: &date, Frames: 3600, Phase: 4, Seed: -7, Replay: "replay_1.fbr"},
+		{Name: "Pekuja, the \"pirate\"", Value: 750, Mode: "story"},
+		{Name: "JDruid", Value: 2000, Mode: "story", Date: &date, Frames: 10, Invincible: true},
+		{Name: "Shark", Value: 400, Mode: "endless", Seed: 1 << 40},
+		{Name: "Seagull", Value: 300, Mode: campaign, Phase: 2},
+	}
+	want := make(map[string][]string)
+	for _, mode := range []string{"story", "endless", campaign} {
+		var rs []Rank
+		for _, r := range ranks {
+			if r.Mode == mode && !r.Invincible {
+				rs = append(rs, r)
+			}
+		}
+		for _, r := range ranks {
+			if r.Mode == mode && r.Invincible {
+				rs = append(rs, r)
+			}
+		}
+		want[mode] = entryKeys(rs)
+	}
+
+	for _, format := range []string{"csv", "json"} {
+		scoresDir(t)
+		fillScores(t, ranks)
+		export := filepath.Join(t.TempDir(), "scores."+format)
+		if err := exportScores(export, "", ""); err != nil {
+			t.Fatalf("%v: %v", format, err)
+		}
+
+		scoresDir(t)
+		if err := importScores([]string{export}, "", "", false); err != nil {
+			t.Fatalf("%v: %v", format, err)
+		}
+		for mode, keys := range want {
+			got := entryKeys(LoadScores(mode).entries())
+			if !reflect.DeepEqual(got, keys) {
+				t.Errorf("%v: %v came back as\n%v\nwant\n%v", format, mode, strings.Join(got, "\n"), strings.Join(keys, "\n"))
+			}
+		}
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	date := time.Date(2024, 5, 17, 18, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name string
+		csv  string
+		want []Rank
+		ok   bool
+	}{
+		{"name and score", "name,score\nHectigo,1500\n", []Rank{{Name: "Hectigo", Value: 1500}}, true},
+		{"any order", "score,mode,name\n750,endless,Pekuja\n", []Rank{{Name: "Pekuja", Value: 750, Mode: "endless"}}, true},
+		{
+			"empty optional values",
+			"name,score,date,frames,phase,seed,invincible,replay\nShark,400,,,,,,\n",
+			[]Rank{{Name: "Shark", Value: 400}},
+			true,
+		},
+		{
+			"all columns",
+			"mode,name,score,date,frames,phase,seed,invincible,replay\nstory,JDruid,1000,2024-05-17T21:04:05+03:00,60,3,-9,true,r.fbr\n",
+			[]Rank{{Mode: "story", Name: "JDruid", Value: 1000, Date: &date, Frames: 60, Phase: 3, Seed: -9, Invincible: true, Replay: "r.fbr"}},
+			true,
+		},
+		{"header only", "name,score\n", nil, true},
+		{"empty", "", nil, false},
+		{"missing score column", "name,mode\nHectigo,story\n", nil, false},
+		{"missing name column", "score\n10\n", nil, false},
+		{"unknown column", "name,score,lives\nHectigo,1,3\n", nil, false},
+		{"bad score", "name,score\nHectigo,lots\n", nil, false},
+		{"bad date", "name,score,date\nHectigo,1,yesterday\n", nil, false},
+		{"bad invincible", "name,score,invincible\nHectigo,1,maybe\n", nil, false},
+		{"short record", "name,score\nHectigo\n", nil, false},
+		{"blank name", "name,score\n,1500\n", nil, false},
+		{"blank score", "name,score\nHectigo,\n", nil, false},
+		{"spaces for a score", "name,score\nHectigo,  \n", nil, false},
+	}
+	for _, tt := range tests {
+		got, err := parseCSV([]byte(tt.csv))
+		switch {
+		case tt.ok && err != nil:
+			t.Errorf("%v: %v", tt.name, err)
+		case !tt.ok && err == nil:
+			t.Errorf("%v: parsed %+v", tt.name, got)
+		case tt.ok && !reflect.DeepEqual(entryKeys(got), entryKeys(tt.want)):
+			t.Errorf("%v: got %v, want %v", tt.name, entryKeys(got), entryKeys(tt.want))
+		}
+	}
+
+	_, err := parseCSV([]byte("name,score\nHectigo,1500\nPekuja,750\nJDruid,\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 4: score:") {
+		t.Errorf("blank score on line 4: %v", err)
+	}
+}
+
+func TestImportMerge(t *testing.T) {
+	day := time.Date(2024, 5, 17, 12, 0, 0, 0, time.UTC)
+	later := day.Add(time.Hour)
+	stored := []Rank{
+		{Name: "A", Value: 100, Mode: "story", Date: &day},
+		{Name: "B", Value: 50, Mode: "story", Date: &day},
+		{Name: "E", Value: 10, Mode: "endless"},
+	}
+	file := []Rank{
+		{Name: "B", Value: 50, Mode: "story", Date: &day},
+		{Name: "B", Value: 50, Mode: "story", Date: &later},
+		{Name: "C", Value: 70, Mode: "story"},
+		{Name: "D", Value: 20},
+		{Name: "F", Value: 30, Mode: "endless"},
+	}
+
+	tests := []struct {
+		name    string
+		merge   bool
+		mode    string
+		story   []string
+		endless []string
+		ok      bool
+	}{
+		{"import replaces", false, "story", []string{"C", "B", "B", "D"}, []string{"E"}, true},
+		{"merge skips runs the table has", true, "story", []string{"A", "C", "B", "B", "D"}, []string{"E"}, true},
+		{"import other mode", false, "endless", []string{"A", "B"}, []string{"F", "D"}, true},
+		{"merge other mode", true, "endless", []string{"A", "B"}, []string{"F", "D", "E"}, true},
+		{"no mode for entry", true, "", []string{"A", "B"}, []string{"E"}, false},
+	}
+	for _, tt := range tests {
+		scoresDir(t)
+		fillScores(t, stored)
+		buf, err := json.Marshal(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filename := filepath.Join(t.TempDir(), "scores.json")
+		if err := os.WriteFile(filename, buf, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		err = importScores([]string{filename}, "", tt.mode, tt.merge)
+		switch {
+		case tt.ok && err != nil:
+			t.Errorf("%v: %v", tt.name, err)
+		case !tt.ok && err == nil:
+			t.Errorf("%v: imported", tt.name)
+		}
+
+		for mode, want := range map[string][]string{"story": tt.story, "endless": tt.endless} {
+			var names []string
+			for _, r := range LoadScores(mode).Ranks {
+				names = append(names, r.Name)
+			}
+			if !reflect.DeepEqual(names, want) {
+				t.Errorf("%v: %v table is %v, want %v", tt.name, mode, names, want)
+			}
+		}
+	}
+}
+
+// TestStoredScoresMissing lists, exports and merges into tables missing on
+// disk and checks none of the placeholders of the game show up.
+func TestStoredScoresMissing(t *testing.T) {
+	scoresDir(t)
+	for _, mode := range []string{"", "story", "nowhere"} {
+		tables, err := storedScores(mode)
+		if err != nil {
+			t.Fatalf("%q: %v", mode, err)
+		}
+		for _, table := range tables {
+			if e := table.entries(); len(e) > 0 {
+				t.Errorf("%q: %v table holds %+v", mode, table.Mode, e)
+			}
+		}
+
+		var buf strings.Builder
+		if err := listScores(&buf, mode); err != nil {
+			t.Fatal(err)
+		}
+		if lines := strings.Count(buf.String(), "\n"); lines != 1 {
+			t.Errorf("%q: list printed %v lines:\n%v", mode, lines, buf.String())
+		}
+
+		export := filepath.Join(t.TempDir(), "scores.json")
+		if err := exportScores(export, "", mode); err != nil {
+			t.Fatal(err)
+		}
+		out, err := os.ReadFile(export)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := strings.TrimSpace(string(out)); s != "[]" {
+			t.Errorf("%q: exported %v", mode, s)
+		}
+	}
+
+	filename := filepath.Join(t.TempDir(), "scores.csv")
+	if err := os.WriteFile(filename, []byte("name,score\nHectigo,1500\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := importScores([]string{filename}, "", "nowhere", true); err != nil {
+		t.Fatal(err)
+	}
+	tables, err := storedScores("nowhere")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := entryKeys(tables[0].entries()); len(names) != 1 {
+		t.Errorf("merged into a missing table: %v", names)
+	}
+}
+
+// TestResetScores resets an unreadable table, which listing refuses, and
+// checks -mode only empties that table.
+func TestResetScores(t *testing.T) {
+	dir := scoresDir(t)
+	fillScores(t, []Rank{{Name: "A", Value: 1, Mode: "story"}, {Name: "E", Value: 2, Mode: "endless"}})
+	broken := filepath.Join(dir, ScoreFile("story")+".json")
+	if err := os.WriteFile(broken, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storedScores("story"); err == nil {
+		t.Error("listed an unreadable table")
+	}
+
+	if err := resetScores("story"); err != nil {
+		t.Fatal(err)
+	}
+	for mode, want := range map[string]int{"story": 0, "endless": 1} {
+		tables, err := storedScores(mode)
+		if err != nil {
+			t.Fatalf("%v: %v", mode, err)
+		}
+		if n := len(tables[0].entries()); n != want {
+			t.Errorf("%v has %v entries after resetting story, want %v", mode, n, want)
+		}
+	}
+
+	if err := resetScores(""); err != nil {
+		t.Fatal(err)
+	}
+	if tables, err := storedScores("endless"); err != nil || len(tables[0].entries()) != 0 {
+		t.Errorf("endless after resetting all: %v", err)
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rank is an entry of a high score table. Besides the name and score it
+// records how the run went: the map it was played on, when it ended, how
+// many frames it lasted, the phase it reached, the seed, whether the
+// player was invincible and the replay saved for it, if any. Entries
+// migrated from the old text format only know the name and score.
+type Rank struct {
+	Name       string     `json:"name"`
+	Value      int        `json:"score"`
+	Mode       string     `json:"mode,omitempty"`
+	Date       *time.Time `json:"date,omitempty"`
+	Frames     int        `json:"frames,omitempty"`
+	Phase      int        `json:"phase,omitempty"`
+	Seed       int64      `json:"seed,omitempty"`
+	Invincible bool       `json:"invincible,omitempty"`
+	Replay     string     `json:"replay,omitempty"`
+}
+
+// Assisted reports whether the run had help the game offers as a cheat.
+// Assisted runs are kept in a table of their own.
+func (r *Rank) Assisted() bool {
+	return r.Invincible
+}
+
+// Duration returns how long the run lasted in game time.
+func (r *Rank) Duration() time.Duration {
+	return time.Duration(r.Frames) * time.Second / Fps
+}
+
+// Clock returns the duration as minutes and seconds, or "" when it is
+// not known.
+func (r *Rank) Clock() string {
+	if r.Frames <= 0 {
+		return ""
+	}
+	d := r.Duration().Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+type RankSlice []Rank
+
+func (s RankSlice) Len() int           { return len(s) }
+func (s RankSlice) Less(i, j int) bool { return s[i].Value > s[j].Value }
+func (s RankSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// Update files the new entry and returns its place in the table, or -1
+// when it did not make it.
+func (s *RankSlice) Update(entry Rank) int {
+	t := *s
+	if entry.Value < 0 || (len(t) >= MaxScores && entry.Value < t[len(t)-1].Value) {
+		return -1
+	}
+
+	rank := 0
+	for rank < len(t) && t[rank].Value >= entry.Value {
+		rank++
+	}
+	if rank >= MaxScores {
+		return -1
+	}
+
+	t = append(t, Rank{})
+	copy(t[rank+1:], t[rank:])
+	t[rank] = entry
+	if len(t) > MaxScores {
+		t = t[:MaxScores]
+	}
+	*s = t
+	return rank
+}
+
+// Version 1 tables kept assisted runs among the others, version 2 tables
+// keep them apart.
+const scoresVersion = 2
+
+type scoresFile struct {
+	Version  int    `json:"version"`
+	Mode     string `json:"mode,omitempty"`
+	Scores   []Rank `json:"scores"`
+	Assisted []Rank `json:"assisted,omitempty"`
+}
+
+// ScoreTable is the stored high score table of a map, with the honest and
+// the assisted runs apart.
+type ScoreTable struct {
+	Mode     string
+	Ranks    RankSlice
+	Assisted RankSlice
+	filename string
+
+	// broken is set when the table on disk could not be read, so the
+	// placeholder table is not saved over it
+	broken bool
+}
+
+var dummyScores = []Rank{
+	{Name: "Funny Boat", Value: 2000},
+	{Name: "Hectigo", Value: 1500},
+	{Name: "JDruid", Value: 1000},
+	{Name: "Pekuja", Value: 750},
+	{Name: "Pirate", Value: 500},
+	{Name: "Shark", Value: 400},
+	{Name: "Seagull", Value: 300},
+	{Name: "Naval Mine", Value: 200},
+	{Name: "Cannonball", Value: 100},
+	{Name: "Puffy the Cloud", Value: 50},
+}
+
+// ScoreFile returns the name of the table of a map in the config
+// directory, without the extension. Maps in the resource directory are
+// known by their name. Map files elsewhere add a hash of their absolute
+// path to the base name, so maps with the same name in different
+// directories keep tables of their own.
+func ScoreFile(mode string) string {
+	switch mode {
+	case "story":
+		return "scores"
+	case "endless":
+		return "endless_scores"
+	}
+
+	filename := MapPath(mode)
+	if filename != mode {
+		return "scores_" + mode
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		abs = filepath.Clean(filename)
+	}
+	h := fnv.New32a()
+	h.Write([]byte(abs))
+	base := filepath.Base(filename)
+	return fmt.Sprintf("scores_%v_%08x", strings.TrimSuffix(base, filepath.Ext(base)), h.Sum32())
+}
+
+func (t *ScoreTable) Table(assisted bool) *RankSlice {
+	if assisted {
+		return &t.Assisted
+	}
+	return &t.Ranks
+}
+
+// Add files the entry of a finished run in the table it belongs to and
+// returns it, or nil when it did not make it.
+func (t *ScoreTable) Add(entry Rank) *Rank {
+	table := t.Table(entry.Assisted())
+	rank := table.Update(entry)
+	if rank < 0 {
+		return nil
+	}
+	return &(*table)[rank]
+}
+
+// LoadScores reads the table of a map, migrating the text table older
+// versions kept when there is no JSON one yet. A missing table starts out
+// with placeholder entries.
+func LoadScores(mode string) *ScoreTable {
+	t, err := readScoreTable(mode, ScoreFile(mode))
+	if err != nil {
+		t.Ranks = append(t.Ranks[:0], dummyScores...)
+		t.Assisted = t.Assisted[:0]
+		t.broken = !os.IsNotExist(err)
+	}
+	return t
+}
+
+// readScoreTable reads the table of a map from the given file in the
+// config directory, without the extension, migrating a text table. The
+// error of a table missing on disk satisfies os.IsNotExist.
+func readScoreTable(mode, file string) (t *ScoreTable, err error) {
+	t = &ScoreTable{Mode: mode, filename: file}
+
+	var filename string
+
+	log.SetPrefix("scores: ")
+	defer func() {
+		if err != nil {
+			log.Print("load failure: ", err)
+		} else {
+			log.Printf("load %q", filename)
+		}
+
+		for _, s := range []*RankSlice{&t.Ranks, &t.Assisted} {
+			sort.Stable(*s)
+			if len(*s) > MaxScores {
+				*s = (*s)[:MaxScores]
+			}
+		}
+	}()
+
+	path, err := config.Path()
+	if err != nil {
+		return t, err
+	}
+
+	filename = filepath.Join(path, t.filename+".json")
+	buf, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		legacy := filepath.Join(path, t.filename)
+		t.Ranks, err = loadLegacyScores(legacy, mode)
+		if err == nil {
+			log.Printf("migrating %q to %q", legacy, filename)
+			sort.Stable(t.Ranks)
+			t.Save()
+		}
+		return t, err
+	}
+	if err != nil {
+		return t, err
+	}
+
+	t.Ranks, t.Assisted, err = parseScores(buf)
+	if err != nil {
+		err = fmt.Errorf("%v: %v", filename, err)
+	}
+	return t, err
+}
+
+// parseScores returns the honest and the assisted table, separating the
+// two for version 1 files.
+func parseScores(buf []byte) (ranks, assisted []Rank, err error) {
+	var f scoresFile
+
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.DisallowUnknownFields()
+	err = dec.Decode(&f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case f.Version == 0:
+		return nil, nil, fmt.Errorf("missing version")
+	case f.Version > scoresVersion:
+		return nil, nil, fmt.Errorf("version %v is newer than the supported version %v", f.Version, scoresVersion)
+	}
+
+	for _, r := range f.Scores {
+		if r.Assisted() {
+			f.Assisted = append(f.Assisted, r)
+		} else {
+			ranks = append(ranks, r)
+		}
+	}
+	return ranks, f.Assisted, nil
+}
+
+// loadLegacyScores reads the old format of alternating name and score
+// lines. Entries with a malformed score are reported and dropped.
+func loadLegacyScores(filename, mode string) ([]Rank, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var ranks []Rank
+	var line [2]string
+
+	s := bufio.NewScanner(f)
+	n := 0
+loop:
+	for {
+		for i := 0; i < 2; i++ {
+			if !s.Scan() {
+				break loop
+			}
+			line[i] = s.Text()
+			n++
+		}
+
+		value, err := strconv.Atoi(strings.TrimSpace(line[1]))
+		if err != nil {
+			log.Printf("%v:%v: dropping entry %q: %v", filename, n, line[0], err)
+			continue
+		}
+		name := strings.TrimSpace(line[0])
+		ranks = append(ranks, Rank{Name: name, Value: value, Mode: mode})
+	}
+	return ranks, s.Err()
+}
+
+// Clear empties both tables, also of a table that could not be read.
+func (t *ScoreTable) Clear() {
+	t.Ranks = RankSlice{}
+	t.Assisted = RankSlice{}
+	t.broken = false
+}
+
+func (t *ScoreTable) Save() (err error) {
+	var filename string
+
+	if t.broken {
+		return fmt.Errorf("not saving over the unreadable %v.json", t.filename)
+	}
+
+	log.SetPrefix("scores: ")
+	defer func() {
+		if err != nil {
+			log.Print("save error: ", err)
+		} else {
+			log.Printf("saved to %q", filename)
+		}
+	}()
+
+	path, err := config.Path()
+	if err != nil {
+		return
+	}
+
+	buf, err := json.MarshalIndent(scoresFile{scoresVersion, t.Mode, t.Ranks, t.Assisted}, "", "\t")
+	if err != nil {
+		return
+	}
+
+	filename = filepath.Join(path, t.filename+".json")
+	err = os.WriteFile(filename, append(buf, '\n'), 0644)
+	return
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// scoresDir gives the test an empty config directory of its own.
+func scoresDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	prev := config.Dir
+	t.Cleanup(func() { config.Dir = prev })
+	config.Dir = dir
+	return dir
+}
+
+// sameRanks compares entries, taking no entries and an empty list alike.
+func sameRanks(a, b []Rank) bool {
+	return len(a) == 0 && len(b) == 0 || reflect.DeepEqual(a, b)
+}
+
+// fullTable returns MaxScores entries scoring 1000 down to 10 in steps of
+// ten, named after their score.
+func fullTable() RankSlice {
+	var s RankSlice
+	for i := 0; i < MaxScores; i++ {
+		v := (MaxScores - i) * 10
+		s = append(s, Rank{Name: fmt.Sprint(v), Value: v})
+	}
+	return s
+}
+
+func TestRankSliceUpdate(t *testing.T) {
+	tests := []struct {
+		name  string
+		table RankSlice
+		entry Rank
+		rank  int
+		size  int
+	}{
+		{"empty", nil, Rank{Name: "new", Value: 5}, 0, 1},
+		{"negative", nil, Rank{Name: "new", Value: -1}, -1, 0},
+		{"top", fullTable(), Rank{Name: "new", Value: 2000}, 0, MaxScores},
+		{"last place", fullTable(), Rank{Name: "new", Value: 15}, MaxScores - 1, MaxScores},
+		{"below last", fullTable(), Rank{Name: "new", Value: 5}, -1, MaxScores},
+		{"tie with last", fullTable(), Rank{Name: "new", Value: 10}, -1, MaxScores},
+		{"tie goes after", fullTable(), Rank{Name: "new", Value: 500}, 51, MaxScores},
+		{"tie not full", fullTable()[:MaxScores-1], Rank{Name: "new", Value: 20}, MaxScores - 1, MaxScores},
+		{"one short", fullTable()[:MaxScores-1], Rank{Name: "new", Value: 1}, MaxScores - 1, MaxScores},
+	}
+	for _, tt := range tests {
+		s := append(RankSlice(nil), tt.table...)
+		rank := s.Update(tt.entry)
+		switch {
+		case rank != tt.rank:
+			t.Errorf("%v: ranked %v, want %v", tt.name, rank, tt.rank)
+		case len(s) != tt.size:
+			t.Errorf("%v: table has %v entries, want %v", tt.name, len(s), tt.size)
+		case rank >= 0 && s[rank].Name != "new":
+			t.Errorf("%v: %q at rank %v", tt.name, s[rank].Name, rank)
+		}
+		for i := 1; i < len(s); i++ {
+			if s[i].Value > s[i-1].Value {
+				t.Fatalf("%v: rank %v scores %v over %v", tt.name, i, s[i].Value, s[i-1].Value)
+			}
+		}
+	}
+}
+
+func TestParseScores(t *testing.T) {
+	tests := []struct {
+		name     string
+		buf      string
+		ranks    []Rank
+		assisted []Rank
+		ok       bool
+	}{
+		{
+			"version 1 splits assisted entries out",
+			`{"version": 1, "scores": [
+				{"name": "a", "score": 30},
+				{"name": "b", "score": 20, "invincible": true},
+				{"name": "c", "score": 10}
+			]}`,
+			[]Rank{{Name: "a", Value: 30}, {Name: "c", Value: 10}},
+			[]Rank{{Name: "b", Value: 20, Invincible: true}},
+			true,
+		},
+		{
+			"version 2 keeps both tables",
+			`{"version": 2, "mode": "story",
+				"scores": [{"name": "a", "score": 30}],
+				"assisted": [{"name": "b", "score": 20, "invincible": true}]}`,
+			[]Rank{{Name: "a", Value: 30}},
+			[]Rank{{Name: "b", Value: 20, Invincible: true}},
+			true,
+		},
+		{"missing version", `{"scores": []}`, nil, nil, false},
+		{"newer version", `{"version": 3, "scores": []}`, nil, nil, false},
+		{"unknown field", `{"version": 2, "scores": [{"name": "a", "score": 1, "lives": 3}]}`, nil, nil, false},
+		{"malformed", `{"version": 2, "scores": [`, nil, nil, false},
+	}
+	for _, tt := range tests {
+		ranks, assisted, err := parseScores([]byte(tt.buf))
+		switch {
+		case tt.ok && err != nil:
+			t.Errorf("%v: %v", tt.name, err)
+		case !tt.ok && err == nil:
+			t.Errorf("%v: parsed", tt.name)
+		case tt.ok && (!sameRanks(ranks, tt.ranks) || !sameRanks(assisted, tt.assisted)):
+			t.Errorf("%v: got %+v and %+v, want %+v and %+v", tt.name, ranks, assisted, tt.ranks, tt.assisted)
+		}
+	}
+}
+
+func TestLoadLegacyScores(t *testing.T) {
+	tests := []struct {
+		name   string
+		legacy string
+		want   []Rank
+	}{
+		{"entries", "Hectigo\n1500\nPekuja\n750\n", []Rank{{Name: "Hectigo", Value: 1500}, {Name: "Pekuja", Value: 750}}},
+		{"unsorted", "low\n10\nhigh\n20\n", []Rank{{Name: "high", Value: 20}, {Name: "low", Value: 10}}},
+		{"spaces", "  Shark \n 400 \n", []Rank{{Name: "Shark", Value: 400}}},
+		{"malformed score", "a\n100\nb\nlots\nc\n50\n", []Rank{{Name: "a", Value: 100}, {Name: "c", Value: 50}}},
+		{"missing score", "a\n100\nb\n", []Rank{{Name: "a", Value: 100}}},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		dir := scoresDir(t)
+		err := os.WriteFile(filepath.Join(dir, "endless_scores"), []byte(tt.legacy), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := range tt.want {
+			tt.want[i].Mode = "endless"
+		}
+		table := LoadScores("endless")
+		if !sameRanks(table.Ranks, tt.want) {
+			t.Errorf("%v: migrated %+v, want %+v", tt.name, table.Ranks, tt.want)
+		}
+
+		// the migrated table is saved and read back from then on
+		buf, err := os.ReadFile(filepath.Join(dir, "endless_scores.json"))
+		if err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		ranks, _, err := parseScores(buf)
+		if err != nil || !sameRanks(ranks, tt.want) {
+			t.Errorf("%v: saved %+v, %v", tt.name, ranks, err)
+		}
+	}
+}
+
+func TestScoreFile(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a", "convoy.json")
+	b := filepath.Join(dir, "b", "convoy.json")
+
+	tests := []struct {
+		x, y string
+		same bool
+	}{
+		{"story", "endless", false},
+		{"convoy", "convoy", true},
+		{"convoy", a, false},
+		{a, b, false},
+		{a, filepath.Join(dir, "b", "..", "a", "convoy.json"), true},
+		{a, filepath.Join(dir, "a", ".", "convoy.json"), true},
+	}
+	for _, tt := range tests {
+		x, y := ScoreFile(tt.x), ScoreFile(tt.y)
+		if (x == y) != tt.same {
+			t.Errorf("%q is %q and %q is %q", tt.x, x, tt.y, y)
+		}
+	}
+
+	if f := ScoreFile(a); filepath.Base(f) != f || f[:len("scores_convoy_")] != "scores_convoy_" {
+		t.Errorf("%q is kept in %q", a, f)
+	}
+}
+
+// TestStoredScoresFindsCampaigns saves tables of two maps with the same
+// name and finds both by the map stored in them.
+func TestStoredScoresFindsCampaigns(t *testing.T) {
+	scoresDir(t)
+	maps := []string{"convoy", filepath.Join("a", "convoy.json"), filepath.Join("b", "convoy.json")}
+	for i, m := range maps {
+		table := LoadScores(m)
+		table.Clear()
+		table.Add(Rank{Name: m, Value: i + 1})
+		if err := table.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tables, err := storedScores("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[string]bool)
+	for _, table := range tables[2:] {
+		if len(table.Ranks) != 1 || table.Ranks[0].Name != table.Mode {
+			t.Errorf("table of %q holds %+v", table.Mode, table.Ranks)
+		}
+		found[table.Mode] = true
+	}
+	for _, m := range maps {
+		if !found[m] {
+			t.Errorf("no table found for %q", m)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+const (
+	MaxWaveTrains = 4
+	MinSeaHeight  = H / 10
+	MaxSeaHeight  = H * 2 / 3
+)
+
+// WaveTrain is one of the sine waves that add up to the swell. Amplitude
+// and Wavelength are in pixels and Speed in pixels per second towards the
+// left of the screen.
+type WaveTrain struct {
+	Amplitude  float64 `json:"amplitude"`
+	Wavelength float64 `json:"wavelength"`
+	Speed      float64 `json:"speed"`
+}
+
+// SeaState is what the sea settles into: the wave trains rolling around a
+// base Height above the bottom of the screen, and a Tide raising or, when
+// negative, lowering that height in pixels per second. A Height of 0 keeps
+// the water where it is.
+type SeaState struct {
+	Height float64     `json:"height"`
+	Tide   float64     `json:"tide"`
+	Waves  []WaveTrain `json:"waves"`
+}
+
+// DefaultSea is the swell of the menus and of maps that only give a wave
+// amplitude as their weather.
+var DefaultSea = SeaState{
+	Height: H / 24 * 8,
+	Waves:  []WaveTrain{{Amplitude: H / 8, Wavelength: defaultWavelength, Speed: defaultSpeed}},
+}
+
+// The swell the game always had turns its wave 0.06 radians a frame.
+const (
+	defaultWavelength = 0.02 * W * W / (2 * math.Pi)
+	defaultSpeed      = 0.06 * Fps * defaultWavelength / (2 * math.Pi)
+)
+
+var seaPresets map[string]*SeaState
+
+// WeatherSea returns the default sea with the given wave amplitude.
+func WeatherSea(amplitude float64) *SeaState {
+	s := DefaultSea
+	s.Waves = []WaveTrain{s.Waves[0]}
+	s.Waves[0].Amplitude = amplitude
+	return &s
+}
+
+// LoadSeaState returns the named entry of the sea state table in the
+// resource directory.
+func LoadSeaState(name string) (*SeaState, error) {
+	log.SetPrefix("sea: ")
+	if seaPresets == nil {
+		filename := filepath.Join(config.Resource, "sea.json")
+		buf, err := os.ReadFile(filename)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		seaPresets, err = parseSeaStates(buf)
+		if err != nil {
+			log.Fatalf("%v: %v", filename, err)
+		}
+	}
+
+	s, found := seaPresets[name]
+	if !found {
+		return nil, fmt.Errorf("no sea state named %q", name)
+	}
+	return s, nil
+}
+
+func parseSeaStates(buf []byte) (map[string]*SeaState, error) {
+	var t map[string]*SeaState
+
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&t)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, s := range t {
+		err := s.check()
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", name, err)
+		}
+	}
+	return t, nil
+}
+
+// parseSea reads a sea state of a map, written either as the name of an
+// entry in the sea state table or in full.
+func parseSea(buf json.RawMessage) (*SeaState, error) {
+	if len(buf) > 0 && buf[0] == '"' {
+		var name string
+		err := json.Unmarshal(buf, &name)
+		if err != nil {
+			return nil, err
+		}
+		return LoadSeaState(name)
+	}
+
+	s := new(SeaState)
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	return s, s.check()
+}
+
+func (s *SeaState) check() error {
+	switch {
+	case s.Height != 0 && (s.Height < MinSeaHeight || s.Height > MaxSeaHeight):
+		return fmt.Errorf("height %v is not between %v and %v", s.Height, MinSeaHeight, MaxSeaHeight)
+	case len(s.Waves) > MaxWaveTrains:
+		return fmt.Errorf("%v wave trains, at most %v are supported", len(s.Waves), MaxWaveTrains)
+	}
+
+	for i, t := range s.Waves {
+		switch {
+		case t.Amplitude < 0:
+			return fmt.Errorf("wave %v: amplitude %v is negative", i, t.Amplitude)
+		case t.Wavelength <= 0:
+			return fmt.Errorf("wave %v: wavelength %v is not positive", i, t.Wavelength)
+		}
+	}
+	return nil
+}
@@ -10,7 +10,17 @@ type Seagull struct {
 	Step uint
 }
 
-func (s *Seagull) Init() {
+func init() {
+	RegisterActor("seagull", ActorKind{New: newSeagull})
+}
+
+func newSeagull(g *Game, ev SpawnEvent) Actor {
+	s := &Seagull{}
+	s.Init(g.rng)
+	return s
+}
+
+func (s *Seagull) Init(rng *rand.Rand) {
 	var p, i []*Image
 
 	for n := 1; n <= 3; n++ {
@@ -21,12 +31,32 @@ func (s *Seagull) Init() {
 	s.Entity.Reset()
 	s.Pictures = p
 	s.Images = i
-	s.Pos = Point{W, H/10 + rand.Float64()*H/10}
+	s.Pos = Point{W, H/10 + rng.Float64()*H/10}
 	s.Vel = Point{-2, 0}
 	s.Life = 1
 }
 
-func (s *Seagull) Update() {
+func (s *Seagull) Body() *Entity { return &s.Entity }
+func (s *Seagull) Layer() Layer  { return LayerEnemy }
+
+func (s *Seagull) Gone() bool {
+	return s.Pos.Right(s.Image()) < 0 || s.Dead
+}
+
+func (s *Seagull) Touch(g *Game) {}
+
+func (s *Seagull) Hit(g *Game, c *Cannon) bool {
+	s.Damage(1)
+	s.Vel.X += c.Vel.X * 0.6
+	s.Vel.Y += c.Vel.Y * 0.4
+	return true
+}
+
+func (s *Seagull) Value(c *Cannon) int {
+	return 75
+}
+
+func (s *Seagull) Update(g *Game) {
 	s.Step++
 
 	if !s.Dying {
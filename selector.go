@@ -1,8 +1,6 @@
 package main
 
 import (
-	"fmt"
-
 	"github.com/qeedquan/go-media/sdl"
 	"github.com/qeedquan/go-media/sdl/sdlimage/sdlcolor"
 	"github.com/qeedquan/go-media/sdl/sdlttf"
@@ -14,10 +12,12 @@ type Selector struct {
 	font    *sdlttf.Font
 	menu    []string
 	cursor  int
-	handler func(sdl.Event)
+	top     int
+	grab    bool
+	handler func(sdl.Event, []ActionEvent)
 }
 
-func (s *Selector) Init(font *sdlttf.Font, handler func(sdl.Event)) {
+func (s *Selector) Init(font *sdlttf.Font, handler func(sdl.Event, []ActionEvent)) {
 	s.State.Init()
 	s.logo = LoadImage("logo")
 	s.font = font
@@ -28,11 +28,13 @@ func (s *Selector) Run(menu []string, cursor int) int {
 	s.Reset()
 	s.menu = menu
 	s.cursor = cursor
+	s.top = 0
+	s.grab = false
 
 	for !s.Done {
 		s.draw()
 		s.State.Update()
-		s.event()
+		s.WaitFrame(Tick, s.event)
 	}
 	return s.cursor
 }
@@ -40,17 +42,28 @@ func (s *Selector) Run(menu []string, cursor int) int {
 func (s *Selector) draw() {
 	s.State.Draw()
 
-	for i := range s.menu {
+	const link = "http://funnyboat.sourceforge.net/"
+	_, th := MeasureText(smallFont, link)
+
+	// menus too long to fit between the logo and the link scroll
+	// to keep the cursor in view
+	rh := s.font.Height()
+	rows := Max((H-s.logo.H-th)/rh, 1)
+	if s.cursor >= 0 {
+		if s.cursor < s.top {
+			s.top = s.cursor
+		} else if s.cursor >= s.top+rows {
+			s.top = s.cursor - rows + 1
+		}
+	}
+	for i := s.top; i < len(s.menu) && i < s.top+rows; i++ {
 		s.render(i)
 	}
 
 	p := Point{(W - float64(s.logo.W)) / 2, 0}
 	s.logo.Blit(p)
 
-	const link = "http://funnyboat.sourceforge.net/"
-	tw, th, _ := smallFont.SizeUTF8(link)
-	p = Point{(W - float64(tw)) / 2, H - float64(th)}
-	blitText(smallFont, int(p.X), int(p.Y), sdlcolor.Black, link)
+	DrawText(Small().Aligned(AlignCenter), W/2, H-th, link)
 
 	screen.Present()
 }
@@ -61,47 +74,44 @@ func (s *Selector) render(id int) {
 		color = sdl.Color{255, 127, 0x00, 0xff}
 	}
 
-	title := s.menu[id]
-	tw, th, _ := s.font.SizeUTF8(title)
-	x := (W - tw) / 2
-	y := s.logo.H + id*th
+	y := s.logo.H + (id-s.top)*s.font.Height()
+	style := TextStyle{Font: s.font, Color: color, Align: AlignCenter}
+	DrawText(style, W/2, y, s.menu[id])
+}
+
+func (s *Selector) event(ev sdl.Event) {
+	if _, ok := ev.(sdl.QuitEvent); ok {
+		s.quit()
+		return
+	}
 
-	blitText(s.font, x, y, color, fmt.Sprintf("%v", title))
+	actions := config.Bindings.Translate(ev)
+	if !s.grab && s.navigate(actions) {
+		return
+	}
+	s.handler(ev, actions)
 }
 
-func (s *Selector) event() {
-	s.NextFrame = false
-	for !s.NextFrame {
-		select {
-		case <-frame.C:
-			s.NextFrame = true
-		default:
+// navigate handles moving the cursor and leaving the menu, reporting
+// whether the event was used up doing so.
+func (s *Selector) navigate(actions []ActionEvent) bool {
+	for _, a := range actions {
+		if !a.Down {
+			continue
 		}
-
-		for {
-			ev := sdl.PollEvent()
-			if ev == nil {
-				break
-			}
-			switch ev := ev.(type) {
-			case sdl.QuitEvent:
-				s.quit()
-			case sdl.KeyDownEvent:
-				switch ev.Sym {
-				case sdl.K_ESCAPE:
-					s.quit()
-				case sdl.K_DOWN:
-					s.move(1)
-				case sdl.K_UP:
-					s.move(-1)
-				default:
-					s.handler(ev)
-				}
-			default:
-				s.handler(ev)
-			}
+		switch a.Action {
+		case ActionBack:
+			s.quit()
+			return true
+		case ActionMoveDown:
+			s.move(1)
+			return true
+		case ActionMoveUp:
+			s.move(-1)
+			return true
 		}
 	}
+	return false
 }
 
 func (s *Selector) move(i int) {
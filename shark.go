@@ -4,7 +4,20 @@ import "math"
 
 type Shark struct {
 	Entity
-	Step int
+	Step     int
+	Surface  bool
+	Buoyancy Buoyancy
+}
+
+func init() {
+	RegisterActor("shark", ActorKind{New: newShark, Variants: []string{"surface"}})
+}
+
+func newShark(g *Game, ev SpawnEvent) Actor {
+	s := &Shark{}
+	s.Init()
+	s.Surface = ev.Variant == "surface"
+	return s
 }
 
 func (s *Shark) Init() {
@@ -17,24 +30,52 @@ func (s *Shark) Init() {
 	s.Sound = LoadSound("kraah")
 	s.Pos = Point{W, 0}
 	s.Life = 1
+	s.Buoyancy = LoadBuoyancy("shark")
 }
 
-func (s *Shark) Update() {
-	m := s.Image()
-	l := WaterRegion(s.Pos, m)
+func (s *Shark) Body() *Entity { return &s.Entity }
+func (s *Shark) Layer() Layer  { return LayerEnemy }
+
+func (s *Shark) Update(g *Game) {
+	s.move()
+
 	if s.Dying {
-		s.UpdateAngle(Lerp(s.Angle, s.TargetAngle, 0.6))
-		s.Pos = s.Pos.Add(s.Vel)
-		s.Vel.Y++
+		m := s.Image()
+		p := Point{s.Pos.CenterX(m), s.Pos.CenterY(m)}
+		g.ensemble.Blood(p)
+	}
+}
 
-		if s.Pos.Bottom(m) > l[1] {
-			s.Vel = s.Vel.Scale(0.8)
-		}
+func (s *Shark) Gone() bool {
+	return s.Pos.Right(s.Image()) < 0 || s.Dead
+}
 
-		if s.Pos.Y >= H {
-			s.Dead = true
-		}
+func (s *Shark) Touch(g *Game) {
+	if !s.Dying && Collision(&g.player.Entity, &s.Entity) {
+		g.damagePlayer()
+		s.Damage(1)
+	}
+}
+
+func (s *Shark) Hit(g *Game, c *Cannon) bool {
+	s.Damage(1)
+	s.Vel.X += c.Vel.X * 0.6
+	s.Vel.Y += c.Vel.Y * 0.4
+	return true
+}
 
+func (s *Shark) Value(c *Cannon) int {
+	return 15
+}
+
+func (s *Shark) move() {
+	m := s.Image()
+	l := WaterRegion(s.Pos, m)
+	if s.Dying {
+		s.Pos.X += s.Vel.X
+		if s.Buoyancy.Sink(&s.Entity) {
+			s.Vel.X *= s.Buoyancy.Drag
+		}
 		return
 	}
 
@@ -49,7 +90,7 @@ func (s *Shark) Update() {
 		}
 	}
 
-	if s.Step%40 == 0 {
+	if s.Step%40 == 0 && !s.Surface {
 		s.Jumping = true
 		s.Vel = Point{-3, -10}
 	}
@@ -37,6 +37,10 @@ func LoadSound(name string) *Sound {
 		return s
 	}
 
+	if !config.Sound {
+		return nil
+	}
+
 	log.SetPrefix("sound: ")
 	filename := filepath.Join(config.Resource, name+".ogg")
 	chunk, err := sdlmixer.LoadWAV(filename)
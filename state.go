@@ -1,9 +1,12 @@
 package main
 
+import "time"
+
 type State struct {
 	Done      bool
 	NextFrame bool
 	Sky       *Image
+	next      time.Time
 }
 
 func (s *State) Init() {
@@ -12,6 +15,7 @@ func (s *State) Init() {
 
 func (s *State) Reset() {
 	s.Done, s.NextFrame = false, false
+	s.next = time.Now()
 }
 
 func (s *State) Quit() {
@@ -1,7 +1,5 @@
 package main
 
-import "math"
-
 type Steamboat struct {
 	Boat
 	MovingLeft  bool
@@ -19,30 +17,21 @@ func (s *Steamboat) Reset() {
 	s.Sound = LoadSound("blub")
 
 	s.Entity.Reset()
+	s.MovingLeft = false
+	s.MovingRight = false
+	s.Blinks = 0
 	s.Life = 5
+	s.Buoyancy = LoadBuoyancy("player")
 	s.Pos = Point{50, 20}
 }
 
 func (s *Steamboat) Update() {
 	m := s.Image()
-	l := WaterRegion(s.Pos, m)
 
 	s.Splash = false
 
 	if s.Dying {
-		s.UpdateAngle(Lerp(s.Angle, s.TargetAngle, 0.9))
-		s.Pos.Y += s.Vel.Y
-		s.Vel.Y++
-
-		bottom := s.Pos.Bottom(m)
-		if bottom > l[1] {
-			s.Vel.Y *= 0.8
-		}
-
-		if s.Pos.Y >= H {
-			s.Dead = true
-		}
-
+		s.Buoyancy.Sink(&s.Entity)
 		return
 	}
 
@@ -54,28 +43,15 @@ func (s *Steamboat) Update() {
 		s.Vel.X = 2
 	}
 
-	bottom := s.Pos.Bottom(m)
-	if bottom > l[1] {
+	if s.Buoyancy.Float(&s.Entity) {
 		if s.Jumping {
 			s.Splash = true
 		}
 		s.Jumping = false
-
-		s.Vel.Y *= 0.8
-		if s.Pos.Y > l[2] {
-			s.Vel.Y -= 2
-		} else {
-			s.Vel.Y -= (bottom - l[1]) * 0.25
-		}
-
-		s.TargetAngle = math.Atan((l[0]-l[2])/32)*Degree + math.Sin(float64(s.T)*0.05)*5
 	} else {
 		s.Jumping = true
 	}
 
-	s.Vel.Y++
-	s.Pos = s.Pos.Add(s.Vel)
-
 	if s.Pos.X < 0 {
 		s.Pos.X = 0
 	}
@@ -84,7 +60,6 @@ func (s *Steamboat) Update() {
 	}
 
 	s.T++
-	s.UpdateAngle(Lerp(s.Angle, s.TargetAngle, 0.8))
 
 	m.SetAlphaMod(255)
 	if s.Blinks != 0 {
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/qeedquan/go-media/sdl"
+	"github.com/qeedquan/go-media/sdl/sdlimage/sdlcolor"
+	"github.com/qeedquan/go-media/sdl/sdlttf"
+)
+
+const MaxCachedText = 256
+
+// Align says where a line of text sits relative to the x it is drawn at.
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// Effect is drawn under the text to keep it readable over the sea.
+type Effect int
+
+const (
+	EffectNone Effect = iota
+	EffectShadow
+	EffectOutline
+)
+
+type TextStyle struct {
+	Font        *sdlttf.Font
+	Color       sdl.Color
+	Align       Align
+	Effect      Effect
+	EffectColor sdl.Color
+
+	// fade is the transparency, so the zero style is opaque
+	fade uint8
+}
+
+// Small and Big return the plain black styles for the two game fonts.
+// They are functions since the fonts are only loaded after startup.
+func Small() TextStyle {
+	return TextStyle{Font: smallFont, Color: sdlcolor.Black}
+}
+
+func Big() TextStyle {
+	return TextStyle{Font: bigFont, Color: sdlcolor.Black}
+}
+
+func (s TextStyle) Aligned(a Align) TextStyle {
+	s.Align = a
+	return s
+}
+
+func (s TextStyle) Colored(c sdl.Color) TextStyle {
+	s.Color = c
+	return s
+}
+
+// Faded returns the style drawn with the given opacity.
+func (s TextStyle) Faded(alpha uint8) TextStyle {
+	s.fade = 255 - alpha
+	return s
+}
+
+func (s TextStyle) Shadow(c sdl.Color) TextStyle {
+	s.Effect = EffectShadow
+	s.EffectColor = c
+	return s
+}
+
+func (s TextStyle) Outline(c sdl.Color) TextStyle {
+	s.Effect = EffectOutline
+	s.EffectColor = c
+	return s
+}
+
+type textKey struct {
+	font  *sdlttf.Font
+	color sdl.Color
+	text  string
+}
+
+type textLine struct {
+	Texture
+	W, H int
+	used uint64
+}
+
+// TextCache keeps rasterized lines around so text that does not change
+// between frames is only rendered once. The least recently drawn line is
+// dropped when the cache is full.
+type TextCache struct {
+	lines map[textKey]*textLine
+	clock uint64
+}
+
+var texts TextCache
+
+func (c *TextCache) Line(font *sdlttf.Font, color sdl.Color, text string) *textLine {
+	if c.lines == nil {
+		c.lines = make(map[textKey]*textLine)
+	}
+	c.clock++
+
+	key := textKey{font, color, text}
+	if l := c.lines[key]; l != nil {
+		l.used = c.clock
+		return l
+	}
+
+	if len(c.lines) >= MaxCachedText {
+		c.evict()
+	}
+
+	texture, w, h, err := screen.RenderText(font, color, text)
+	if err != nil {
+		log.SetPrefix("text: ")
+		log.Fatal(err)
+	}
+	l := &textLine{texture, w, h, c.clock}
+	c.lines[key] = l
+	return l
+}
+
+func (c *TextCache) evict() {
+	var oldest textKey
+	var line *textLine
+	for k, l := range c.lines {
+		if line == nil || l.used < line.used {
+			oldest, line = k, l
+		}
+	}
+	if line != nil {
+		line.Destroy()
+		delete(c.lines, oldest)
+	}
+}
+
+// MeasureText returns the size of text laid out one line per newline.
+func MeasureText(font *sdlttf.Font, text string) (w, h int) {
+	for i, line := range strings.Split(text, "\n") {
+		tw, th, _ := font.SizeUTF8(line)
+		w = Max(w, tw)
+		if i == 0 {
+			h = th
+		} else {
+			h += font.LineSkip()
+		}
+	}
+	return
+}
+
+// DrawText draws text with its first line at y, each line aligned
+// against x.
+func DrawText(s TextStyle, x, y int, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		if line != "" {
+			drawLine(s, x, y, line)
+		}
+		y += s.Font.LineSkip()
+	}
+}
+
+func drawLine(s TextStyle, x, y int, text string) {
+	alpha := 255 - s.fade
+	l := texts.Line(s.Font, s.Color, text)
+	switch s.Align {
+	case AlignCenter:
+		x -= l.W / 2
+	case AlignRight:
+		x -= l.W
+	}
+
+	switch s.Effect {
+	case EffectShadow:
+		e := texts.Line(s.Font, s.EffectColor, text)
+		e.blit(x+1, y+1, alpha)
+	case EffectOutline:
+		e := texts.Line(s.Font, s.EffectColor, text)
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if dx != 0 || dy != 0 {
+					e.blit(x+dx, y+dy, alpha)
+				}
+			}
+		}
+	}
+	l.blit(x, y, alpha)
+}
+
+func (l *textLine) blit(x, y int, alpha uint8) {
+	l.SetAlphaMod(alpha)
+	screen.Blit(l.Texture, sdl.Rect{int32(x), int32(y), int32(l.W), int32(l.H)}, 0)
+	l.SetAlphaMod(255)
+}
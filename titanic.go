@@ -4,6 +4,16 @@ type Titanic struct {
 	Boat
 }
 
+func init() {
+	RegisterActor("titanic", ActorKind{New: newTitanic, Unique: true})
+}
+
+func newTitanic(g *Game, ev SpawnEvent) Actor {
+	t := &Titanic{}
+	t.Init()
+	return t
+}
+
 func (t *Titanic) Init() {
 	p := LoadImage("titanic")
 	i := p.Copy()
@@ -13,11 +23,79 @@ func (t *Titanic) Init() {
 	t.Images = []*Image{i}
 	t.Sound = LoadSound("blub")
 	t.Life = 100
+	t.Buoyancy = LoadBuoyancy("titanic")
 	t.Pos = Point{W, WaterLevel(W)}
 	t.Pos.Y = t.Pos.Bottom(i) - t.Pos.Y
 	t.Vel = Point{-1, 0}
 }
 
-func (t *Titanic) Update() {
-	UpdateEnemyBoat(&t.Entity, 0.007, 1, 0.15, 0.01, true)
+func (t *Titanic) Body() *Entity { return &t.Entity }
+func (t *Titanic) Layer() Layer  { return LayerEnemy }
+
+func (t *Titanic) Update(g *Game) {
+	UpdateEnemyBoat(&t.Boat, true)
+
+	shoot := false
+	angle := 0.0
+	if !t.Dying {
+		switch t.T % 100 {
+		case 0:
+			angle = 50
+			shoot = true
+		case 50:
+			angle = 52.5
+			shoot = true
+		}
+	}
+
+	if shoot {
+		for i := 0; i < 3; i++ {
+			var c Cannon
+			pos := Point{t.Pos.X, t.Pos.CenterY(t.Image())}
+			c.Init(pos, g.player.Angle+float64(i-1)*10-angle, true, false)
+			g.enemyCannons = append(g.enemyCannons, c)
+		}
+	}
+
+	for i := 0; i < 4; i++ {
+		c := Point{
+			t.Pos.CenterX(t.Image()),
+			t.Pos.CenterY(t.Image()),
+		}
+		p := t.Rotate(Point{49 + g.rng.Float64()*9 + 28*float64(i), 25})
+		p = p.Add(c)
+		g.ensemble.Steam(p)
+	}
+
+	if t.Dead {
+		g.gameOver = "Congratulations!\nYou sunk Titanic!"
+	}
+}
+
+func (t *Titanic) Gone() bool {
+	return t.Dead
+}
+
+func (t *Titanic) Touch(g *Game) {}
+
+func (t *Titanic) Hit(g *Game, c *Cannon) bool {
+	snd := LoadSound("poks")
+	snd.Play(0)
+
+	if c.Special {
+		t.Damage(12)
+	} else {
+		t.Damage(1)
+	}
+
+	// nothing goes through the Titanic
+	c.Dead = true
+	return true
+}
+
+func (t *Titanic) Value(c *Cannon) int {
+	if c.Special {
+		return 100
+	}
+	return 7
 }
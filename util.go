@@ -29,6 +29,17 @@ func Max(a, b int) int {
 	return b
 }
 
+func Min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func Clamp(x, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(x, hi))
+}
+
 func Snapshot() {
 	var err error
 	var filename string
@@ -47,31 +58,11 @@ func Snapshot() {
 		return
 	}
 
-	path = filepath.Join(path, "ss")
-	err = os.MkdirAll(path, 0755)
-	if err != nil && !os.IsExist(err) {
-		return
-	}
-
-	glob := filepath.Join(path, "ss_*.png")
-	matches, err := filepath.Glob(glob)
+	filename, err = nextFilename(filepath.Join(path, "ss"), "ss_", ".png")
 	if err != nil {
 		return
 	}
 
-	var v uint64
-	i := uint64(0)
-	for _, m := range matches {
-		b := filepath.Base(m)
-		n, _ := fmt.Sscanf(b, "ss_%v", &v)
-		if n == 1 {
-			if i <= v {
-				i = v + 1
-			}
-		}
-	}
-
-	filename = filepath.Join(path, fmt.Sprint("ss_", i, ".png"))
 	f, err := os.Create(filename)
 	if err != nil {
 		return
@@ -83,14 +74,14 @@ func Snapshot() {
 		}
 	}()
 
-	w, h, err := screen.OutputSize()
+	w, h, err := display.OutputSize()
 	if err != nil {
 		return
 	}
 
 	stride := 4 * w
 	pixels := make([]byte, stride*h)
-	err = screen.ReadPixels(nil, sdl.PIXELFORMAT_ABGR8888, pixels, stride)
+	err = display.ReadPixels(nil, sdl.PIXELFORMAT_ABGR8888, pixels, stride)
 	if err != nil {
 		return
 	}
@@ -114,3 +105,32 @@ func Snapshot() {
 		return
 	}
 }
+
+// nextFilename creates dir if needed and returns a path in it named
+// prefix followed by one more than the highest number already in use.
+func nextFilename(dir, prefix, ext string) (string, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil && !os.IsExist(err) {
+		return "", err
+	}
+
+	glob := filepath.Join(dir, prefix+"*"+ext)
+	matches, err := filepath.Glob(glob)
+	if err != nil {
+		return "", err
+	}
+
+	var v uint64
+	i := uint64(0)
+	for _, m := range matches {
+		b := filepath.Base(m)
+		n, _ := fmt.Sscanf(b, prefix+"%v", &v)
+		if n == 1 {
+			if i <= v {
+				i = v + 1
+			}
+		}
+	}
+
+	return filepath.Join(dir, fmt.Sprint(prefix, i, ext)), nil
+}
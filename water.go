@@ -6,15 +6,68 @@ import (
 	"github.com/qeedquan/go-media/sdl"
 )
 
+// Ripples ride on top of the swell. Each column is a spring pulled back
+// to the wave that passes some of its height on to its neighbours.
+const (
+	RippleTension = 0.025
+	RippleDamping = 0.025
+	RippleSpread  = 0.25
+	RipplePasses  = 8
+	MaxRipple     = 40
+)
+
+// SeaChange is how much of the way to a new sea state is left after a
+// frame.
+const SeaChange = 0.99
+
+// Explosions going off within BlastReach pixels of the surface push it up
+// at BlastSplash, tapering off BlastRadius pixels to each side.
+const (
+	BlastReach  = 10
+	BlastRadius = 16
+	BlastSplash = -1
+)
+
+// waveTrain is a wave of the swell in radians, per pixel for the wave
+// number k and per frame for the angular speed o.
+type waveTrain struct {
+	ta, a float64
+	tk, k float64
+	to, o float64
+	phase float64
+}
+
+// The water is shaded from Shallow near the top of the sea to Deep at the
+// bottom of the screen, with FoamDepth pixels of foam fading out below
+// the crest.
+var (
+	WaterShallow = sdl.Color{30, 80, 200, 100}
+	WaterDeep    = sdl.Color{10, 30, 110, 190}
+	WaterFoam    = sdl.Color{235, 245, 255, 200}
+)
+
+const FoamDepth = 3
+
+// The water is drawn as a strip with an edge of waterEdge vertices at
+// every column: the surface, where the shading starts, the bottom of the
+// screen and the top and bottom of the foam.
+const (
+	waterEdge = 5
+	waterTop  = H / 3
+)
+
 type Water struct {
-	image   *Image
-	levels  []float64
-	ta, a   float64
-	tw, w   float64
-	ts, s   float64
-	tbh, bh float64
-	xm, tm  float64
-	t       float64
+	vertices []sdl.Vertex
+	indices  []int32
+	levels   []float64
+	ripples  []float64
+	prev     []float64
+	speeds   []float64
+	deltas   []float64
+	trains   [MaxWaveTrains]waveTrain
+	tbh, bh  float64
+	tide     float64
+	sea      *SeaState
 }
 
 var (
@@ -25,6 +78,10 @@ func InitWater() {
 	water.Init()
 }
 
+func ResetWater() {
+	water.Reset()
+}
+
 func UpdateWater() {
 	water.Update()
 }
@@ -33,8 +90,24 @@ func DrawWater() {
 	water.Draw()
 }
 
-func SetWaterAmplitude(a float64) {
-	water.SetAmplitude(a)
+// SplashWater pushes the surface around x down at the given speed, or up
+// when it is negative, tapering off to nothing radius pixels to each side.
+func SplashWater(x, radius, speed float64) {
+	water.Splash(x, radius, speed)
+}
+
+// SplashBlast pushes the surface up under an explosion at p that goes off
+// close to it.
+func SplashBlast(p Point) {
+	if math.Abs(p.Y-WaterLevel(p.X)) <= BlastReach {
+		SplashWater(p.X, BlastRadius, BlastSplash)
+	}
+}
+
+// SetSeaState makes the sea settle into s. Setting the state the sea is
+// already heading for again does nothing, so a tide keeps going.
+func SetSeaState(s *SeaState) {
+	water.SetSeaState(s)
 }
 
 func WaterLevel(x float64) float64 {
@@ -50,56 +123,159 @@ func WaterRegion(p Point, i *Image) [3]float64 {
 }
 
 func (w *Water) Init() {
-	w.image = NewImage(W, H)
+	w.vertices = make([]sdl.Vertex, 0, waterEdge*(W+1))
+	w.indices = waterIndices(W + 1)
 	w.levels = make([]float64, W)
+	w.ripples = make([]float64, W)
+	w.prev = make([]float64, W)
+	w.speeds = make([]float64, W)
+	w.deltas = make([]float64, W)
+	w.Reset()
+}
 
-	w.ta = H / 8
-	w.tw = 0.02 * W / (2 * math.Pi)
-	w.ts = 0.06 / (2 * math.Pi) * Fps
-	w.tbh = H / 24 * 8
-	w.a, w.w, w.s, w.bh = w.ta, w.tw, w.ts, w.tbh
+func (w *Water) Reset() {
+	w.sea = nil
+	w.SetSeaState(&DefaultSea)
+	w.bh = w.tbh
+	for i := range w.trains {
+		t := &w.trains[i]
+		t.a, t.k, t.o = t.ta, t.tk, t.to
+		t.phase = 0
+	}
 
-	w.xm = 2 * math.Pi / w.w / W
-	w.tm = 2 * math.Pi / Fps * w.s
+	for x := range w.ripples {
+		w.ripples[x], w.prev[x], w.speeds[x] = 0, 0, 0
+	}
 
 	w.Update()
 }
 
-func (w *Water) Update() {
-	w.image.Bind()
-	defer w.image.Unbind()
+// waterShade returns the color of the water at height y. Crests seldom
+// reach above the top third of the screen, so the shading starts there.
+func waterShade(y float64) sdl.Color {
+	t := Clamp((y-waterTop)/(H-waterTop), 0, 1)
+	return sdl.Color{
+		uint8(Lerp(float64(WaterDeep.R), float64(WaterShallow.R), t)),
+		uint8(Lerp(float64(WaterDeep.G), float64(WaterShallow.G), t)),
+		uint8(Lerp(float64(WaterDeep.B), float64(WaterShallow.B), t)),
+		uint8(Lerp(float64(WaterDeep.A), float64(WaterShallow.A), t)),
+	}
+}
 
-	screen.SetDrawColor(sdl.Color{200, 210, 255, 0})
-	screen.Clear()
+// waterIndices returns the triangles between n edges of waterEdge
+// vertices each: two quads from the surface down through the top of the
+// shading to the bottom, then the foam over them.
+func waterIndices(n int) []int32 {
+	var indices []int32
+	quad := func(a int32) {
+		indices = append(indices, a, a+waterEdge, a+1, a+1, a+waterEdge, a+waterEdge+1)
+	}
+	for i := int32(0); i < int32(n-1); i++ {
+		quad(i * waterEdge)
+		quad(i*waterEdge + 1)
+	}
+	for i := int32(0); i < int32(n-1); i++ {
+		quad(i*waterEdge + 3)
+	}
+	return indices
+}
 
-	screen.SetDrawColor(sdl.Color{20, 60, 180, 110})
-	for x := range w.levels {
-		h := H - (math.Sin(float64(x)*w.xm+w.t*w.tm)*w.a + w.bh)
-		w.levels[x] = h
-		hi, _ := math.Modf(h)
-		w.image.Vline(x, int(hi), H)
+// wave returns the height of the swell at x as it was the given number of
+// steps ago.
+func (w *Water) wave(x, ago float64) float64 {
+	y := w.bh
+	for i := range w.trains {
+		t := &w.trains[i]
+		if t.a != 0 {
+			y += math.Sin(x*t.k+t.phase-t.o*ago) * t.a
+		}
 	}
+	return H - y
+}
 
-	if w.ta != w.a {
-		w.a = Lerp(w.a, w.ta, 0.99)
+func (w *Water) Update() {
+	w.ripple()
+	for x := range w.levels {
+		w.levels[x] = w.wave(float64(x), 0) + w.ripples[x]
 	}
-	if w.tw != w.w {
-		w.w = Lerp(w.w, w.tw, 0.99)
-		w.xm = 2 * math.Pi / w.w / W
+
+	for i := range w.trains {
+		t := &w.trains[i]
+		t.a = Lerp(t.a, t.ta, SeaChange)
+		t.k = Lerp(t.k, t.tk, SeaChange)
+		t.o = Lerp(t.o, t.to, SeaChange)
+		t.phase = math.Mod(t.phase+t.o, 2*math.Pi)
 	}
-	if w.ts != w.s {
-		w.s = Lerp(w.s, w.ts, 0.99)
-		w.tm = 2 * math.Pi / Fps * w.s
+	w.tbh = Clamp(w.tbh+w.tide/Fps, MinSeaHeight, MaxSeaHeight)
+	w.bh = Lerp(w.bh, w.tbh, SeaChange)
+}
+
+func (w *Water) ripple() {
+	copy(w.prev, w.ripples)
+
+	for x := range w.ripples {
+		w.speeds[x] -= RippleTension*w.ripples[x] + RippleDamping*w.speeds[x]
+		w.ripples[x] += w.speeds[x]
 	}
-	if w.tbh != w.bh {
-		w.bh = Lerp(w.bh, w.tbh, 0.99)
+
+	// the change of each column is gathered first so the spread does not
+	// depend on which way the columns are walked
+	n := len(w.ripples)
+	for i := 0; i < RipplePasses; i++ {
+		for x := range w.deltas {
+			d := 0.0
+			if x > 0 {
+				d += w.ripples[x-1] - w.ripples[x]
+			}
+			if x < n-1 {
+				d += w.ripples[x+1] - w.ripples[x]
+			}
+			w.deltas[x] = RippleSpread * d
+		}
+		for x, d := range w.deltas {
+			w.speeds[x] += d
+			w.ripples[x] = Clamp(w.ripples[x]+d, -MaxRipple, MaxRipple)
+		}
 	}
+}
 
-	w.t++
+func (w *Water) Splash(x, radius, speed float64) {
+	x0 := Max(int(x-radius), 0)
+	x1 := Min(int(x+radius), len(w.speeds)-1)
+	for i := x0; i <= x1; i++ {
+		d := (float64(i) - x) / (radius + 1)
+		w.speeds[i] += speed * (1 + math.Cos(d*math.Pi)) / 2
+	}
 }
 
+// Draw shades the water under the surface and the foam under the crests
+// as a single strip of triangles, an edge at every column.
 func (w *Water) Draw() {
-	w.image.Blit(Point{})
+	w.vertices = w.vertices[:0]
+	faded := WaterFoam
+	faded.A = 0
+
+	// levels are for the latest step, draw the waves blended from the
+	// step before like everything else
+	n := len(w.levels)
+	for i := 0; i <= n; i++ {
+		x := Min(i, n-1)
+		y := w.wave(float64(x), 2-blend) + Lerp(w.ripples[x], w.prev[x], blend)
+		y = Clamp(y, 0, H)
+		top := math.Max(y, waterTop)
+		w.vertices = append(w.vertices,
+			waterVertex(i, y, waterShade(y)),
+			waterVertex(i, top, waterShade(top)),
+			waterVertex(i, H, WaterDeep),
+			waterVertex(i, y, WaterFoam),
+			waterVertex(i, y+FoamDepth, faded),
+		)
+	}
+	screen.FillGeometry(w.vertices, w.indices)
+}
+
+func waterVertex(x int, y float64, c sdl.Color) sdl.Vertex {
+	return sdl.Vertex{Position: sdl.FPoint{X: float32(x), Y: float32(y)}, Color: c}
 }
 
 func (w *Water) Level(x float64) float64 {
@@ -112,6 +288,32 @@ func (w *Water) Level(x float64) float64 {
 	return w.levels[xi]
 }
 
-func (w *Water) SetAmplitude(a float64) {
-	w.ta = a
+func (w *Water) SetSeaState(s *SeaState) {
+	if s == w.sea {
+		return
+	}
+	w.sea = s
+
+	for i := range w.trains {
+		t := &w.trains[i]
+		if i >= len(s.Waves) {
+			t.ta = 0
+			continue
+		}
+
+		s := s.Waves[i]
+		t.ta = s.Amplitude
+		t.tk = 2 * math.Pi / s.Wavelength
+		t.to = t.tk * s.Speed / Fps
+
+		// a wave that is not there yet starts with its final shape
+		if t.a < 0.01 {
+			t.k, t.o = t.tk, t.to
+		}
+	}
+
+	if s.Height != 0 {
+		w.tbh = s.Height
+	}
+	w.tide = s.Tide
 }
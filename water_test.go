@@ -0,0 +1,179 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/qeedquan/go-media/sdl"
+)
+
+// geometryRecorder is a headless renderer that keeps the geometry drawn.
+type geometryRecorder struct {
+	nullRenderer
+	calls    int
+	vertices []sdl.Vertex
+	indices  []int32
+}
+
+func (g *geometryRecorder) FillGeometry(vertices []sdl.Vertex, indices []int32) {
+	g.calls++
+	g.vertices = append(g.vertices[:0], vertices...)
+	g.indices = append(g.indices[:0], indices...)
+}
+
+func TestWaterDrawsOneStrip(t *testing.T) {
+	InitHeadless()
+	defer func(s Renderer, b float64) { screen, blend = s, b }(screen, blend)
+	g := &geometryRecorder{}
+	screen = g
+	blend = 1
+
+	// the sea is settled, so the swell of a step ago is exact
+	var w Water
+	w.Init()
+	w.Splash(W/2, 20, 10)
+	for i := 0; i < 100; i++ {
+		w.Update()
+	}
+	w.Draw()
+
+	if g.calls != 1 {
+		t.Fatalf("drew the water in %v calls, want 1", g.calls)
+	}
+	if n := waterEdge * (W + 1); len(g.vertices) != n {
+		t.Fatalf("%v vertices, want %v", len(g.vertices), n)
+	}
+	if n := 3 * 2 * 3 * W; len(g.indices) != n {
+		t.Errorf("%v indices, want %v", len(g.indices), n)
+	}
+	for _, i := range g.indices {
+		if i < 0 || int(i) >= len(g.vertices) {
+			t.Fatalf("index %v out of %v vertices", i, len(g.vertices))
+		}
+	}
+
+	// drawn after a whole step, the surface is where the step left it
+	for _, x := range []int{0, 1, W / 3, W / 2, W - 1} {
+		edge := g.vertices[x*waterEdge : (x+1)*waterEdge]
+		y := float64(edge[0].Position.Y)
+		if want := Clamp(w.Level(float64(x)), 0, H); math.Abs(y-want) > 1e-3 {
+			t.Errorf("surface at %v drawn at %v, want %v", x, y, want)
+		}
+		if edge[0].Color != waterShade(y) || edge[2].Color != WaterDeep {
+			t.Errorf("column %v shaded %v to %v", x, edge[0].Color, edge[2].Color)
+		}
+		if d := edge[4].Position.Y - edge[3].Position.Y; d != FoamDepth || edge[4].Color.A != 0 {
+			t.Errorf("foam at %v is %v deep fading to %v", x, d, edge[4].Color.A)
+		}
+	}
+}
+
+// TestSplashBlast sets off explosions at, under and well above the surface
+// and checks only the ones close to it push the water up around them.
+func TestSplashBlast(t *testing.T) {
+	InitHeadless()
+	defer InitWater()
+
+	tests := []struct {
+		name   string
+		dy     float64
+		splash bool
+	}{
+		{"at the surface", 0, true},
+		{"just above", -BlastReach, true},
+		{"just under", BlastReach / 2, true},
+		{"high above", -3 * BlastReach, false},
+		{"deep under", 3 * BlastReach, false},
+	}
+	for _, tt := range tests {
+		InitWater()
+		x := float64(W / 2)
+		SplashBlast(Point{x, WaterLevel(x) + tt.dy})
+
+		for i, v := range water.speeds {
+			d := math.Abs(float64(i) - x)
+			switch {
+			case !tt.splash && v != 0:
+				t.Fatalf("%v: column %v moves at %v", tt.name, i, v)
+			case tt.splash && d < BlastRadius && v >= 0:
+				t.Fatalf("%v: column %v moves at %v, want up", tt.name, i, v)
+			case tt.splash && d > BlastRadius && v != 0:
+				t.Fatalf("%v: column %v past the blast moves at %v", tt.name, i, v)
+			}
+		}
+	}
+}
+
+// newWaterSheet and drawWaterSprites draw the water the way sprites would,
+// two a column from a sheet of the shading and foam, to compare the strip
+// with.
+func newWaterSheet() Texture {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, H))
+	for y := 0; y < H; y++ {
+		c := waterShade(float64(y))
+		img.SetNRGBA(0, y, color.NRGBA{c.R, c.G, c.B, c.A})
+	}
+	for y := 0; y < FoamDepth; y++ {
+		c := WaterFoam
+		c.A = uint8(int(c.A) * (FoamDepth - y) / FoamDepth)
+		img.SetNRGBA(1, y, color.NRGBA{c.R, c.G, c.B, c.A})
+	}
+
+	texture, err := screen.LoadTexture(img)
+	if err != nil {
+		panic(err)
+	}
+	return texture
+}
+
+func drawWaterSprites(w *Water, sheet Texture, sprites []Sprite) []Sprite {
+	sprites = sprites[:0]
+	for x := range w.levels {
+		y := w.wave(float64(x), 2-blend) + Lerp(w.ripples[x], w.prev[x], blend)
+		hi := int32(Clamp(y, 0, H))
+		sprites = append(sprites,
+			Sprite{sdl.Rect{0, hi, 1, H - hi}, sdl.Rect{int32(x), hi, 1, H - hi}, 255},
+			Sprite{sdl.Rect{1, 0, 1, FoamDepth}, sdl.Rect{int32(x), hi, 1, FoamDepth}, 255},
+		)
+	}
+	screen.BlitBatch(sheet, sprites)
+	return sprites
+}
+
+// BenchmarkWaterDraw draws a frame of rough water as a strip and, for
+// comparison, as a batch of sprites.
+func BenchmarkWaterDraw(b *testing.B) {
+	benchmarkDisplay(b)
+
+	var w Water
+	w.Init()
+	w.SetSeaState(WeatherSea(H / 4))
+	for i := 0; i < 100; i++ {
+		w.Update()
+	}
+
+	b.Run("strip", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			screen.SetDrawColor(sdl.Color{})
+			screen.Clear()
+			w.Draw()
+			screen.Present()
+		}
+	})
+
+	b.Run("sprites", func(b *testing.B) {
+		sheet := newWaterSheet()
+		defer sheet.Destroy()
+		sprites := make([]Sprite, 0, 2*W)
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			screen.SetDrawColor(sdl.Color{})
+			screen.Clear()
+			sprites = drawWaterSprites(&w, sheet, sprites)
+			screen.Present()
+		}
+	})
+}